@@ -0,0 +1,200 @@
+package chip8
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/// Assembly is the result of assembling a source file: the machine code
+/// ready for LoadROM and any breakpoints the source flagged with a
+/// leading "!" on the line.
+///
+type Assembly struct {
+	ROM         []byte
+	Breakpoints []uint16
+}
+
+type asmLine struct {
+	label string
+	op    string
+	args  []string
+	brk   bool
+	addr  uint16
+}
+
+/// Assemble reads a simple CHIP-8 assembly source file and produces an
+/// Assembly. It supports the classic mnemonics (LD, ADD, JP, CALL, SE,
+/// SNE, OR, AND, XOR, SUB, SHR, SHL, RND, DRW, SKP, SKNP, CLS, RET) plus
+/// labels and a leading "!" to mark a breakpoint on that instruction.
+///
+func Assemble(path string) *Assembly {
+	file, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	var lines []*asmLine
+	labels := make(map[string]uint16)
+	addr := uint16(EntryPoint)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(text, ";"); i >= 0 && !strings.HasPrefix(text, "!") {
+			text = strings.TrimSpace(text[:i])
+		}
+		if text == "" {
+			continue
+		}
+
+		brk := strings.HasPrefix(text, "!")
+		if brk {
+			text = strings.TrimSpace(text[1:])
+		}
+
+		if strings.HasSuffix(text, ":") {
+			labels[strings.TrimSuffix(text, ":")] = addr
+			continue
+		}
+
+		fields := strings.Fields(strings.ReplaceAll(text, ",", " "))
+		line := &asmLine{op: strings.ToUpper(fields[0]), args: fields[1:], brk: brk, addr: addr}
+
+		lines = append(lines, line)
+		addr += 2
+	}
+
+	if err := scanner.Err(); err != nil {
+		panic(err)
+	}
+
+	var rom []byte
+	var breakpoints []uint16
+
+	for _, line := range lines {
+		op := encode(line, labels)
+
+		rom = append(rom, byte(op>>8), byte(op&0xFF))
+
+		if line.brk {
+			breakpoints = append(breakpoints, line.addr)
+		}
+	}
+
+	return &Assembly{ROM: rom, Breakpoints: breakpoints}
+}
+
+func encode(line *asmLine, labels map[string]uint16) uint16 {
+	reg := func(s string) uint16 {
+		s = strings.TrimPrefix(strings.ToUpper(s), "V")
+		n, _ := strconv.ParseUint(s, 16, 8)
+		return uint16(n)
+	}
+
+	addr := func(s string) uint16 {
+		if a, ok := labels[s]; ok {
+			return a
+		}
+		n, _ := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 16)
+		return uint16(n)
+	}
+
+	byteVal := func(s string) uint16 {
+		n, _ := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 8)
+		return uint16(n)
+	}
+
+	a := line.args
+
+	switch line.op {
+	case "CLS":
+		return 0x00E0
+	case "RET":
+		return 0x00EE
+	case "JP":
+		if len(a) == 2 {
+			return 0xB000 | addr(a[1])
+		}
+		return 0x1000 | addr(a[0])
+	case "CALL":
+		return 0x2000 | addr(a[0])
+	case "SE":
+		if strings.HasPrefix(strings.ToUpper(a[1]), "V") {
+			return 0x5000 | reg(a[0])<<8 | reg(a[1])<<4
+		}
+		return 0x3000 | reg(a[0])<<8 | byteVal(a[1])
+	case "SNE":
+		if strings.HasPrefix(strings.ToUpper(a[1]), "V") {
+			return 0x9000 | reg(a[0])<<8 | reg(a[1])<<4
+		}
+		return 0x4000 | reg(a[0])<<8 | byteVal(a[1])
+	case "ADD":
+		if strings.ToUpper(a[0]) == "I" {
+			return 0xF01E | reg(a[1])<<8
+		}
+		if strings.HasPrefix(strings.ToUpper(a[1]), "V") {
+			return 0x8004 | reg(a[0])<<8 | reg(a[1])<<4
+		}
+		return 0x7000 | reg(a[0])<<8 | byteVal(a[1])
+	case "OR":
+		return 0x8001 | reg(a[0])<<8 | reg(a[1])<<4
+	case "AND":
+		return 0x8002 | reg(a[0])<<8 | reg(a[1])<<4
+	case "XOR":
+		return 0x8003 | reg(a[0])<<8 | reg(a[1])<<4
+	case "SUB":
+		return 0x8005 | reg(a[0])<<8 | reg(a[1])<<4
+	case "SHR":
+		return 0x8006 | reg(a[0])<<8
+	case "SUBN":
+		return 0x8007 | reg(a[0])<<8 | reg(a[1])<<4
+	case "SHL":
+		return 0x800E | reg(a[0])<<8
+	case "RND":
+		return 0xC000 | reg(a[0])<<8 | byteVal(a[1])
+	case "DRW":
+		n, _ := strconv.ParseUint(a[2], 16, 8)
+		return 0xD000 | reg(a[0])<<8 | reg(a[1])<<4 | uint16(n)
+	case "SKP":
+		return 0xE09E | reg(a[0])<<8
+	case "SKNP":
+		return 0xE0A1 | reg(a[0])<<8
+	case "LD":
+		return encodeLD(a, reg, addr, byteVal)
+	}
+
+	panic(fmt.Sprintf("unknown mnemonic %q", line.op))
+}
+
+func encodeLD(a []string, reg, addr, byteVal func(string) uint16) uint16 {
+	dst := strings.ToUpper(a[0])
+
+	switch {
+	case dst == "I":
+		return 0xA000 | addr(a[1])
+	case dst == "DT":
+		return 0xF015 | reg(a[1])<<8
+	case dst == "ST":
+		return 0xF018 | reg(a[1])<<8
+	case strings.ToUpper(a[1]) == "DT":
+		return 0xF007 | reg(a[0])<<8
+	case strings.ToUpper(a[1]) == "K":
+		return 0xF00A | reg(a[0])<<8
+	case strings.ToUpper(a[1]) == "F":
+		return 0xF029 | reg(a[0])<<8
+	case strings.ToUpper(a[1]) == "B":
+		return 0xF033 | reg(a[0])<<8
+	case strings.ToUpper(a[1]) == "[I]":
+		return 0xF065 | reg(a[0])<<8
+	case strings.ToUpper(a[0]) == "[I]":
+		return 0xF055 | reg(a[1])<<8
+	case strings.HasPrefix(dst, "V") && strings.HasPrefix(strings.ToUpper(a[1]), "V"):
+		return 0x8000 | reg(a[0])<<8 | reg(a[1])<<4
+	default:
+		return 0x6000 | reg(a[0])<<8 | byteVal(a[1])
+	}
+}
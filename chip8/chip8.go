@@ -0,0 +1,262 @@
+package chip8
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+)
+
+/// Mode selects which instruction set and memory layout the VM emulates.
+///
+type Mode int
+
+const (
+	ModeChip8 Mode = iota
+	ModeSchip
+	ModeXOChip
+)
+
+const (
+	/// Standard CHIP-8 / SCHIP address space.
+	///
+	MemSize = 4096
+
+	/// XO-CHIP programs may address a much larger bank of RAM.
+	///
+	MemSizeXOChip = 65536
+
+	/// Programs are loaded starting at this address.
+	///
+	EntryPoint = 0x200
+
+	/// Low-res (CHIP-8/SCHIP) display dimensions.
+	///
+	LoResWidth, LoResHeight = 64, 32
+
+	/// Hi-res (SCHIP/XO-CHIP) display dimensions.
+	///
+	HiResWidth, HiResHeight = 128, 64
+
+	/// Number of color planes XO-CHIP can draw to.
+	///
+	NumPlanes = 2
+)
+
+/// CHIP_8 is the virtual machine: registers, memory, display and the
+/// handful of peripherals (timers, keypad, RPL flags) every opcode can
+/// touch.
+///
+type CHIP_8 struct {
+	Mode   Mode
+	Quirks Quirks
+
+	Memory []byte
+	V      [16]byte
+	I      uint16
+	PC     uint16
+	Stack  []uint16
+	SP     int
+	DT, ST byte
+	Keys   [16]bool
+
+	/// RPL is the SCHIP "user flags" register file, persisted to disk by
+	/// FX75/FX85 alongside the ROM.
+	///
+	RPL [16]byte
+
+	/// Hires is true once 00FF has switched the display into 128x64.
+	///
+	Hires bool
+
+	/// Planes holds one bitmask per pixel per XO-CHIP color plane.
+	///
+	Planes [NumPlanes][]byte
+
+	/// Plane is the bitmask (1, 2 or 3) FN01 selected for drawing/scroll.
+	///
+	Plane byte
+
+	/// Pattern is the 16-byte audio buffer loaded by F002 and played
+	/// back at the pitch FX3A sets; PatternSet is false until a ROM has
+	/// actually issued F002, so a Frontend knows to keep using its
+	/// default tone until then instead of playing 128 bits of silence.
+	///
+	Pattern    [16]byte
+	Pitch      byte
+	PatternSet bool
+
+	Breakpoints map[uint16]bool
+
+	/// Path is the ROM's location on disk, used to find the .rpl sidecar
+	/// file for FX75/FX85.
+	///
+	Path string
+
+	/// SHA256 identifies the loaded ROM for quirks lookup and netplay
+	/// handshakes.
+	///
+	SHA256 [sha256.Size]byte
+
+	/// Exited is set by the SCHIP 00FD opcode, asking the host to close
+	/// the program cleanly rather than keep running.
+	///
+	Exited bool
+
+	/// Quantum selects how Process and the debugger's step command
+	/// divide up execution: whole instructions, fetch/decode/execute
+	/// phases, or whole frames.
+	///
+	Quantum Quantum
+
+	/// HistoryCap bounds the StepBack ring buffer; 0 means
+	/// DefaultHistoryCap.
+	///
+	HistoryCap int
+
+	waitKey     int  // register FX0A is waiting on, or -1
+	vblankSpent bool // true once a DXYN has drawn this frame (DisplayWait quirk)
+
+	phase   int    // fetch/decode/execute phase when Quantum == QuantumCycle
+	fetched uint16 // opcode latched by the fetch phase
+
+	history []*snapshot // step-back ring buffer, oldest first
+	cur     *snapshot    // in-progress snapshot for the step being executed
+}
+
+/// ROM is a decoded program ready to load into a CHIP_8.
+///
+type ROM struct {
+	Data []byte
+	Mode Mode
+}
+
+/// NewCHIP_8 allocates a VM for the given mode with cleared memory,
+/// registers and display planes.
+///
+func NewCHIP_8(mode Mode) *CHIP_8 {
+	size := MemSize
+	if mode == ModeXOChip {
+		size = MemSizeXOChip
+	}
+
+	vm := &CHIP_8{
+		Mode:        mode,
+		Quirks:      DefaultQuirks(mode),
+		Memory:      make([]byte, size),
+		Stack:       make([]uint16, 0, 16),
+		PC:          EntryPoint,
+		Breakpoints: make(map[uint16]bool),
+		Plane:       1,
+		waitKey:     -1,
+	}
+
+	for i := range vm.Planes {
+		vm.Planes[i] = make([]byte, HiResWidth*HiResHeight)
+	}
+
+	copy(vm.Memory, font)
+	copy(vm.Memory[fontBigOffset:], fontBig)
+
+	return vm
+}
+
+/// LoadROM loads raw ROM bytes, auto-detecting the instruction set from
+/// the opcodes used (XO-CHIP's F000 NNNN and FN01 are unambiguous; SCHIP
+/// is assumed whenever a hi-res or RPL opcode appears), unless mode has
+/// already been pinned by the caller via LoadROMMode.
+///
+func LoadROM(data []byte) *CHIP_8 {
+	return LoadROMMode(data, DetectMode(data))
+}
+
+/// LoadROMMode loads raw ROM bytes into a freshly allocated VM of the
+/// given mode.
+///
+func LoadROMMode(data []byte, mode Mode) *CHIP_8 {
+	vm := NewCHIP_8(mode)
+	vm.SHA256 = sha256.Sum256(data)
+
+	copy(vm.Memory[EntryPoint:], data)
+
+	return vm
+}
+
+/// LoadFile reads a ROM from disk and loads it, remembering the path so
+/// RPL flags can be persisted alongside it.
+///
+func LoadFile(path string) *CHIP_8 {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	vm := LoadROM(data)
+	vm.Path = path
+	vm.loadRPL()
+
+	return vm
+}
+
+/// LoadFileMode reads a ROM from disk into a VM pinned to the given
+/// mode, bypassing DetectMode. Used when the caller has passed an
+/// explicit -mode flag rather than relying on auto-detection.
+///
+func LoadFileMode(path string, mode Mode) *CHIP_8 {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	vm := LoadROMMode(data, mode)
+	vm.Path = path
+	vm.loadRPL()
+
+	return vm
+}
+
+/// DetectMode scans a ROM's opcodes for SCHIP/XO-CHIP-only instructions
+/// so Load() can pick a sensible default without a -mode flag.
+///
+func DetectMode(data []byte) Mode {
+	mode := ModeChip8
+
+	for i := 0; i+1 < len(data); i += 2 {
+		op := uint16(data[i])<<8 | uint16(data[i+1])
+
+		switch {
+		case op&0xF000 == 0xF000 && op&0x00FF == 0x00:
+			return ModeXOChip // F000 NNNN
+		case op&0xF0FF == 0xF001:
+			return ModeXOChip // FN01 plane select
+		case op&0xF0FF == 0xF002:
+			return ModeXOChip // F002 audio pattern
+		case op == 0x00FF, op == 0x00FE:
+			mode = ModeSchip
+		case op&0xF00F == 0xD000:
+			mode = ModeSchip // DXY0
+		case op&0xF0FF == 0xF075, op&0xF0FF == 0xF085:
+			mode = ModeSchip
+		}
+	}
+
+	return mode
+}
+
+/// Width returns the active display width for the current resolution.
+///
+func (vm *CHIP_8) Width() int {
+	if vm.Hires {
+		return HiResWidth
+	}
+
+	return LoResWidth
+}
+
+/// Height returns the active display height for the current resolution.
+///
+func (vm *CHIP_8) Height() int {
+	if vm.Hires {
+		return HiResHeight
+	}
+
+	return LoResHeight
+}
@@ -0,0 +1,137 @@
+package chip8
+
+/// DisplayPlane returns the pixel bitmask for plane i (0 or 1) of the
+/// active display, sized to the current resolution. CHIP-8 and SCHIP
+/// only ever draw to plane 0; XO-CHIP's FN01 can target either or both.
+///
+func (vm *CHIP_8) DisplayPlane(i int) []byte {
+	return vm.Planes[i][:vm.Width()*vm.Height()]
+}
+
+func (vm *CHIP_8) clearPlanes(mask byte) {
+	for i, plane := range vm.Planes {
+		if mask&(1<<uint(i)) != 0 {
+			for j := range plane {
+				vm.pokePixel(i, j, 0)
+			}
+		}
+	}
+}
+
+/// scroll shifts every selected plane by (dx, dy) pixels, used by
+/// 00Cn/00FB/00FC. Columns/rows scrolled off the edge are filled with 0.
+///
+func (vm *CHIP_8) scroll(dx, dy int) {
+	w, h := vm.Width(), vm.Height()
+
+	for i, plane := range vm.Planes {
+		if vm.Plane&(1<<uint(i)) == 0 {
+			continue
+		}
+
+		shifted := make([]byte, len(plane))
+
+		for y := 0; y < h; y++ {
+			sy := y - dy
+			if sy < 0 || sy >= h {
+				continue
+			}
+
+			for x := 0; x < w; x++ {
+				sx := x - dx
+				if sx < 0 || sx >= w {
+					continue
+				}
+
+				shifted[y*w+x] = plane[sy*w+sx]
+			}
+		}
+
+		for j, value := range shifted {
+			vm.pokePixel(i, j, value)
+		}
+	}
+}
+
+/// draw implements DXYN (and the SCHIP/XO-CHIP DXY0 16x16 variant),
+/// XORing a sprite from memory at I into every plane FN01 selected.
+/// VF is set to 1/0 for an 8-wide sprite, or, for the 16x16 DXY0
+/// variant, to the number of rows with a collision.
+///
+func (vm *CHIP_8) draw(x, y, n byte) {
+	w, h := vm.Width(), vm.Height()
+	px, py := int(vm.V[x])%w, int(vm.V[y])%h
+
+	wide := n == 0 // DXY0: 16x16 sprite
+	rows, cols := int(n), 8
+	if wide {
+		rows, cols = 16, 16
+	}
+
+	addr := vm.I
+	collisions := 0
+
+	for i := 0; i < rows; i++ {
+		py2 := py + i
+		if py2 >= h {
+			if vm.Quirks.Clipping {
+				break
+			}
+
+			py2 %= h
+		}
+
+		var rowBits uint16
+		if wide {
+			rowBits = uint16(vm.Memory[addr])<<8 | uint16(vm.Memory[addr+1])
+			addr += 2
+		} else {
+			rowBits = uint16(vm.Memory[addr]) << 8
+			addr++
+		}
+
+		rowCollision := false
+
+		for plane, bits := range vm.Planes {
+			if vm.Plane&(1<<uint(plane)) == 0 {
+				continue
+			}
+
+			for bit := 0; bit < cols; bit++ {
+				px2 := px + bit
+				if px2 >= w {
+					if vm.Quirks.Clipping {
+						break
+					}
+
+					px2 %= w
+				}
+
+				if rowBits&(0x8000>>uint(bit)) == 0 {
+					continue
+				}
+
+				idx := py2*w + px2
+				if bits[idx] != 0 {
+					rowCollision = true
+				}
+
+				vm.pokePixel(plane, idx, bits[idx]^1)
+			}
+		}
+
+		if rowCollision {
+			collisions++
+		}
+	}
+
+	if wide {
+		vm.V[0xF] = byte(collisions)
+	} else if collisions > 0 {
+		vm.V[0xF] = 1
+	} else {
+		vm.V[0xF] = 0
+	}
+
+	vm.vblankSpent = true
+}
@@ -0,0 +1,460 @@
+package chip8
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+/// MaxRollback bounds how many frames of local prediction a Netplay
+/// session buffers before a correction can no longer be replayed; a
+/// confirmation older than this is simply accepted without resimulating.
+///
+const MaxRollback = 8
+
+/// InstructionsPerFrame is how many opcodes Netplay.Advance simulates
+/// per 60Hz frame, independent of wall-clock timing, so both sides of a
+/// session execute identical, reproducible steps.
+///
+const InstructionsPerFrame = 9
+
+const (
+	netplayMsgHandshake byte = iota
+	netplayMsgInput
+)
+
+/// netplayHandshake is exchanged once when a session connects, so both
+/// sides can confirm they're simulating the same ROM under the same
+/// rules before any input is exchanged.
+///
+type netplayHandshake struct {
+	SHA256 [sha256.Size]byte
+	Quirks Quirks
+}
+
+/// frameState is a full copy of everything that affects simulation,
+/// taken at a frame boundary so Netplay can rewind to it; unlike the
+/// step-back ring's per-instruction deltas, rollback needs to jump
+/// straight to an arbitrary past frame, so a whole-state copy is simpler
+/// even though it's heavier.
+///
+type frameState struct {
+	memory []byte
+	planes [NumPlanes][]byte
+	v      [16]byte
+	i      uint16
+	pc     uint16
+	stack  []uint16
+	dt, st byte
+	plane  byte
+	hires  bool
+	keys   [16]bool
+
+	waitKey     int
+	vblankSpent bool
+}
+
+func (vm *CHIP_8) captureFrameState() *frameState {
+	mem := make([]byte, len(vm.Memory))
+	copy(mem, vm.Memory)
+
+	var planes [NumPlanes][]byte
+	for i := range vm.Planes {
+		planes[i] = make([]byte, len(vm.Planes[i]))
+		copy(planes[i], vm.Planes[i])
+	}
+
+	stack := make([]uint16, len(vm.Stack))
+	copy(stack, vm.Stack)
+
+	return &frameState{
+		memory:      mem,
+		planes:      planes,
+		v:           vm.V,
+		i:           vm.I,
+		pc:          vm.PC,
+		stack:       stack,
+		dt:          vm.DT,
+		st:          vm.ST,
+		plane:       vm.Plane,
+		hires:       vm.Hires,
+		keys:        vm.Keys,
+		waitKey:     vm.waitKey,
+		vblankSpent: vm.vblankSpent,
+	}
+}
+
+func (vm *CHIP_8) restoreFrameState(s *frameState) {
+	copy(vm.Memory, s.memory)
+
+	for i := range vm.Planes {
+		copy(vm.Planes[i], s.planes[i])
+	}
+
+	vm.V = s.v
+	vm.I = s.i
+	vm.PC = s.pc
+	vm.Stack = append(vm.Stack[:0], s.stack...)
+	vm.DT = s.dt
+	vm.ST = s.st
+	vm.Plane = s.plane
+	vm.Hires = s.hires
+	vm.Keys = s.keys
+	vm.waitKey = s.waitKey
+	vm.vblankSpent = s.vblankSpent
+}
+
+/// runFrame simulates one 60Hz frame's worth of instructions at a fixed
+/// rate, rather than however many the wall-clock driven Process loop
+/// would run, so two Netplay peers stay in lockstep. Like Process, it
+/// stops executing (but still ticks timers) once the VM blocks on an
+/// FX0A that this frame's merged Keys didn't resolve.
+///
+func (vm *CHIP_8) runFrame() {
+	for i := 0; i < InstructionsPerFrame; i++ {
+		if !vm.resolveWaitKey() {
+			break
+		}
+
+		vm.stepInstruction()
+	}
+
+	vm.Tick60()
+}
+
+/// frameRecord is one simulated frame's worth of bookkeeping: the state
+/// just before it ran, the input it ran with, and whether the remote
+/// half of that input has actually been confirmed by the peer yet.
+///
+type frameRecord struct {
+	frame     uint32
+	before    *frameState
+	local     [16]bool
+	remote    [16]bool
+	confirmed bool
+}
+
+/// Netplay shares a VM's keypad between two emulator instances over
+/// UDP using rollback netcode: every frame it predicts the remote
+/// side's input as whatever was last confirmed, simulates ahead of
+/// that confirmation, and rewinds to the earliest frame whose prediction
+/// disagreed with what the peer actually sent (replaying forward from
+/// there) once the real value arrives.
+///
+type Netplay struct {
+	vm     *CHIP_8
+	conn   *net.UDPConn
+	peer   *net.UDPAddr
+	isHost bool
+
+	nextFrame       uint32
+	predictedRemote [16]bool
+
+	records []*frameRecord // oldest first, bounded to MaxRollback+1
+}
+
+/// Host listens on addr and blocks until a peer connects and completes
+/// the ROM/quirks handshake.
+///
+func Host(vm *CHIP_8, addr string) (*Netplay, error) {
+	laddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	np := &Netplay{vm: vm, conn: conn, isHost: true}
+
+	if err := np.handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return np, nil
+}
+
+/// Connect dials a Netplay session hosted at addr and blocks until the
+/// ROM/quirks handshake completes.
+///
+func Connect(vm *CHIP_8, addr string) (*Netplay, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	np := &Netplay{vm: vm, conn: conn, peer: raddr}
+
+	if err := np.handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return np, nil
+}
+
+/// Close releases the underlying UDP socket.
+///
+func (np *Netplay) Close() error {
+	return np.conn.Close()
+}
+
+func (np *Netplay) handshake() error {
+	local := netplayHandshake{SHA256: np.vm.SHA256, Quirks: np.vm.Quirks}
+
+	body, err := json.Marshal(local)
+	if err != nil {
+		return err
+	}
+
+	msg := append([]byte{netplayMsgHandshake}, body...)
+
+	var remote netplayHandshake
+
+	if np.isHost {
+		buf := make([]byte, 4096)
+
+		n, from, err := np.conn.ReadFromUDP(buf)
+		if err != nil {
+			return err
+		}
+
+		np.peer = from
+
+		if err := decodeHandshake(buf[:n], &remote); err != nil {
+			return err
+		}
+
+		if _, err := np.conn.WriteToUDP(msg, np.peer); err != nil {
+			return err
+		}
+	} else {
+		if _, err := np.conn.Write(msg); err != nil {
+			return err
+		}
+
+		buf := make([]byte, 4096)
+
+		n, err := np.conn.Read(buf)
+		if err != nil {
+			return err
+		}
+
+		if err := decodeHandshake(buf[:n], &remote); err != nil {
+			return err
+		}
+	}
+
+	if remote.SHA256 != local.SHA256 {
+		return fmt.Errorf("netplay: peer is running a different ROM")
+	}
+
+	if remote.Quirks != local.Quirks {
+		return fmt.Errorf("netplay: peer is running a different quirks profile")
+	}
+
+	return nil
+}
+
+func decodeHandshake(msg []byte, h *netplayHandshake) error {
+	if len(msg) < 1 || msg[0] != netplayMsgHandshake {
+		return fmt.Errorf("netplay: expected a handshake packet")
+	}
+
+	return json.Unmarshal(msg[1:], h)
+}
+
+/// Advance simulates the next frame: it merges local with the best
+/// guess of the remote side's input, runs exactly InstructionsPerFrame
+/// opcodes plus a 60Hz timer tick, records a snapshot so a later
+/// correction can rewind to just before this frame, and sends local out
+/// to the peer tagged with this frame's number.
+///
+func (np *Netplay) Advance(local [16]bool) error {
+	frame := np.nextFrame
+	remote := np.predictedRemote
+
+	before := np.vm.captureFrameState()
+	np.vm.Keys = mergeKeys(local, remote)
+	np.vm.runFrame()
+
+	np.records = append(np.records, &frameRecord{frame: frame, before: before, local: local, remote: remote})
+	if excess := len(np.records) - (MaxRollback + 1); excess > 0 {
+		np.records = np.records[excess:]
+	}
+
+	np.nextFrame++
+
+	if err := np.sendInput(frame, local); err != nil {
+		return err
+	}
+
+	np.reconcile(np.poll())
+
+	return nil
+}
+
+/// reconcile applies every remote input confirmed since the last
+/// Advance call, rewinding and resimulating once from the earliest
+/// frame whose prediction turned out wrong.
+///
+func (np *Netplay) reconcile(msgs []netplayInput) {
+	rewindTo := -1
+
+	for _, m := range msgs {
+		np.predictedRemote = m.keys
+
+		idx := np.indexOf(m.frame)
+		if idx < 0 {
+			continue // too old to still have a snapshot for; accept it and move on
+		}
+
+		rec := np.records[idx]
+		if rec.remote == m.keys {
+			rec.confirmed = true
+			continue // prediction already matched what actually happened
+		}
+
+		rec.remote = m.keys
+		rec.confirmed = true
+
+		if rewindTo < 0 || idx < rewindTo {
+			rewindTo = idx
+		}
+	}
+
+	if rewindTo >= 0 {
+		np.resimulateFrom(rewindTo)
+	}
+}
+
+/// resimulateFrom rewinds to the snapshot just before records[idx] and
+/// replays every frame from there to the present, re-predicting any
+/// frame after idx that still hasn't been confirmed.
+///
+func (np *Netplay) resimulateFrom(idx int) {
+	np.vm.restoreFrameState(np.records[idx].before)
+
+	for i := idx; i < len(np.records); i++ {
+		rec := np.records[i]
+
+		if i > idx {
+			if !rec.confirmed {
+				rec.remote = np.predictedRemote
+			}
+
+			rec.before = np.vm.captureFrameState()
+		}
+
+		np.vm.Keys = mergeKeys(rec.local, rec.remote)
+		np.vm.runFrame()
+	}
+}
+
+func (np *Netplay) indexOf(frame uint32) int {
+	if len(np.records) == 0 {
+		return -1
+	}
+
+	oldest := np.records[0].frame
+	if frame < oldest {
+		return -1
+	}
+
+	idx := int(frame - oldest)
+	if idx >= len(np.records) {
+		return -1
+	}
+
+	return idx
+}
+
+/// netplayInput is one confirmed frame of the peer's keypad state.
+///
+type netplayInput struct {
+	frame uint32
+	keys  [16]bool
+}
+
+func (np *Netplay) sendInput(frame uint32, keys [16]bool) error {
+	buf := make([]byte, 7)
+	buf[0] = netplayMsgInput
+	binary.BigEndian.PutUint32(buf[1:5], frame)
+	binary.BigEndian.PutUint16(buf[5:7], keysToBits(keys))
+
+	if np.isHost {
+		_, err := np.conn.WriteToUDP(buf, np.peer)
+		return err
+	}
+
+	_, err := np.conn.Write(buf)
+	return err
+}
+
+/// poll drains every input packet the peer has sent since the last
+/// call, without blocking for one that hasn't arrived yet.
+///
+func (np *Netplay) poll() []netplayInput {
+	var msgs []netplayInput
+	buf := make([]byte, 16)
+
+	for {
+		np.conn.SetReadDeadline(time.Now())
+
+		n, _, err := np.conn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+
+		if n < 7 || buf[0] != netplayMsgInput {
+			continue
+		}
+
+		msgs = append(msgs, netplayInput{
+			frame: binary.BigEndian.Uint32(buf[1:5]),
+			keys:  bitsToKeys(binary.BigEndian.Uint16(buf[5:7])),
+		})
+	}
+
+	return msgs
+}
+
+func mergeKeys(a, b [16]bool) [16]bool {
+	var m [16]bool
+	for i := range m {
+		m[i] = a[i] || b[i]
+	}
+
+	return m
+}
+
+func keysToBits(keys [16]bool) uint16 {
+	var bits uint16
+	for i, down := range keys {
+		if down {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits
+}
+
+func bitsToKeys(bits uint16) [16]bool {
+	var keys [16]bool
+	for i := range keys {
+		keys[i] = bits&(1<<uint(i)) != 0
+	}
+
+	return keys
+}
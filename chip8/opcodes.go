@@ -0,0 +1,413 @@
+package chip8
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+/// Breakpoint is returned by Process when execution stops on an
+/// address the user has flagged with AddBreakpoint.
+///
+type Breakpoint uint16
+
+func (b Breakpoint) Error() string {
+	return fmt.Sprintf("Breakpoint hit at 0x%04X", uint16(b))
+}
+
+/// AddBreakpoint flags an address so Process halts and reports a
+/// Breakpoint the next time PC reaches it.
+///
+func (vm *CHIP_8) AddBreakpoint(addr uint16) {
+	vm.Breakpoints[addr] = true
+}
+
+/// RemoveBreakpoint clears a previously flagged address.
+///
+func (vm *CHIP_8) RemoveBreakpoint(addr uint16) {
+	delete(vm.Breakpoints, addr)
+}
+
+/// Process fetches, decodes and executes under the VM's active Quantum.
+/// With QuantumInstruction (the default) and QuantumFrame it runs one
+/// whole opcode per call; with QuantumCycle it runs a single
+/// fetch/decode/execute phase per call, so the main loop's clock tick
+/// only completes an instruction once every three calls. It returns a
+/// Breakpoint if the resulting PC is one the user flagged; paused VMs
+/// (and VMs blocked on FX0A) simply return nil without advancing.
+///
+func (vm *CHIP_8) Process(paused bool) error {
+	if paused {
+		return nil
+	}
+
+	if !vm.resolveWaitKey() {
+		return nil
+	}
+
+	if vm.Quantum == QuantumCycle {
+		return vm.stepPhase()
+	}
+
+	return vm.stepInstruction()
+}
+
+/// resolveWaitKey services a pending FX0A, returning false if it's
+/// still blocked on a keypress.
+///
+func (vm *CHIP_8) resolveWaitKey() bool {
+	if vm.waitKey < 0 {
+		return true
+	}
+
+	for i, down := range vm.Keys {
+		if down {
+			vm.V[vm.waitKey] = byte(i)
+			vm.waitKey = -1
+			break
+		}
+	}
+
+	return vm.waitKey < 0
+}
+
+/// stepInstruction fetches, decodes and executes exactly one opcode,
+/// recording its inverse in the history ring so StepBack can undo it.
+///
+func (vm *CHIP_8) stepInstruction() error {
+	op := uint16(vm.Memory[vm.PC])<<8 | uint16(vm.Memory[vm.PC+1])
+
+	if vm.Quirks.DisplayWait && op&0xF000 == 0xD000 && vm.vblankSpent {
+		return nil
+	}
+
+	vm.beginStep()
+	vm.PC += 2
+	vm.execute(op)
+	vm.endStep(op)
+
+	if vm.Breakpoints[vm.PC] {
+		return Breakpoint(vm.PC)
+	}
+
+	return nil
+}
+
+/// stepPhase advances one fetch/decode/execute phase of the current
+/// instruction; the opcode only actually runs (and PC advances) on the
+/// third call, matching how a real CPU debugger single-cycles.
+///
+func (vm *CHIP_8) stepPhase() error {
+	switch vm.phase {
+	case 0: // fetch
+		vm.fetched = uint16(vm.Memory[vm.PC])<<8 | uint16(vm.Memory[vm.PC+1])
+		vm.phase = 1
+	case 1: // decode
+		vm.phase = 2
+	case 2: // execute
+		vm.phase = 0
+
+		if vm.Quirks.DisplayWait && vm.fetched&0xF000 == 0xD000 && vm.vblankSpent {
+			return nil
+		}
+
+		vm.beginStep()
+		vm.PC += 2
+		vm.execute(vm.fetched)
+		vm.endStep(vm.fetched)
+
+		if vm.Breakpoints[vm.PC] {
+			return Breakpoint(vm.PC)
+		}
+	}
+
+	return nil
+}
+
+/// maxFrameInstructions bounds a QuantumFrame Step() so a ROM that
+/// never draws (and so never naturally ends a frame) can't spin
+/// forever.
+///
+const maxFrameInstructions = 100000
+
+/// Step executes a single step sized to the VM's active Quantum: one
+/// instruction, one fetch/decode/execute phase, or a whole frame (run
+/// until the next DXYN consumes the simulated vblank). It's meant for
+/// the debugger's single-step command while paused, independent of the
+/// clock-driven Process calls used while running free.
+///
+func (vm *CHIP_8) Step() error {
+	switch vm.Quantum {
+	case QuantumCycle:
+		return vm.stepPhase()
+	case QuantumFrame:
+		for i := 0; i < maxFrameInstructions; i++ {
+			wasSpent := vm.vblankSpent
+
+			if err := vm.stepInstruction(); err != nil {
+				return err
+			}
+
+			if !wasSpent && vm.vblankSpent {
+				vm.Tick60()
+				return nil
+			}
+		}
+
+		return nil
+	default:
+		return vm.stepInstruction()
+	}
+}
+
+/// Tick60 decrements the delay and sound timers. It should be called
+/// once every 60th of a second, independent of instruction speed.
+///
+func (vm *CHIP_8) Tick60() {
+	if vm.DT > 0 {
+		vm.DT--
+	}
+
+	if vm.ST > 0 {
+		vm.ST--
+	}
+
+	vm.vblankSpent = false
+}
+
+func (vm *CHIP_8) execute(op uint16) {
+	x := byte(op >> 8 & 0xF)
+	y := byte(op >> 4 & 0xF)
+	n := byte(op & 0xF)
+	kk := byte(op & 0xFF)
+	nnn := op & 0xFFF
+
+	switch op & 0xF000 {
+	case 0x0000:
+		vm.op0(op, n)
+	case 0x1000:
+		vm.PC = nnn
+	case 0x2000:
+		vm.Stack = append(vm.Stack, vm.PC)
+		vm.PC = nnn
+	case 0x3000:
+		if vm.V[x] == kk {
+			vm.PC += 2
+		}
+	case 0x4000:
+		if vm.V[x] != kk {
+			vm.PC += 2
+		}
+	case 0x5000:
+		vm.op5(x, y, n)
+	case 0x6000:
+		vm.V[x] = kk
+	case 0x7000:
+		vm.V[x] += kk
+	case 0x8000:
+		vm.op8(x, y, n)
+	case 0x9000:
+		if vm.V[x] != vm.V[y] {
+			vm.PC += 2
+		}
+	case 0xA000:
+		vm.I = nnn
+	case 0xB000:
+		reg := byte(0)
+		if vm.Quirks.JumpVX {
+			reg = x
+		}
+		vm.PC = nnn + uint16(vm.V[reg])
+	case 0xC000:
+		vm.V[x] = byte(rand.Intn(256)) & kk
+	case 0xD000:
+		vm.draw(x, y, n)
+	case 0xE000:
+		vm.opE(x, kk)
+	case 0xF000:
+		vm.opF(op, x, kk)
+	}
+}
+
+func (vm *CHIP_8) op0(op uint16, n byte) {
+	switch {
+	case op == 0x00E0:
+		vm.clearPlanes(vm.Plane)
+	case op == 0x00EE:
+		last := len(vm.Stack) - 1
+		vm.PC = vm.Stack[last]
+		vm.Stack = vm.Stack[:last]
+	case op == 0x00FB:
+		vm.scroll(4, 0)
+	case op == 0x00FC:
+		vm.scroll(-4, 0)
+	case op == 0x00FD:
+		vm.Exited = true
+	case op == 0x00FE:
+		vm.Hires = false
+	case op == 0x00FF:
+		vm.Hires = true
+	case op&0xFFF0 == 0x00C0:
+		vm.scroll(0, int(n))
+	}
+}
+
+func (vm *CHIP_8) op5(x, y, n byte) {
+	switch n {
+	case 0x0:
+		if vm.V[x] == vm.V[y] {
+			vm.PC += 2
+		}
+	case 0x2: // 5XY2 - save register range VX..VY to memory at I
+		for i, r, s := int(x), 0, registerStep(x, y); ; i, r = i+s, r+1 {
+			vm.pokeMemory(vm.I+uint16(r), vm.V[i])
+			if byte(i) == y {
+				break
+			}
+		}
+	case 0x3: // 5XY3 - load register range VX..VY from memory at I
+		for i, r, s := int(x), 0, registerStep(x, y); ; i, r = i+s, r+1 {
+			vm.V[i] = vm.Memory[int(vm.I)+r]
+			if byte(i) == y {
+				break
+			}
+		}
+	}
+}
+
+/// registerStep returns the direction (+1 or -1) 5XY2/5XY3 walk the
+/// register range in, since XO-CHIP allows VX..VY with Y < X.
+///
+func registerStep(x, y byte) int {
+	if y >= x {
+		return 1
+	}
+
+	return -1
+}
+
+func (vm *CHIP_8) op8(x, y, n byte) {
+	switch n {
+	case 0x0:
+		vm.V[x] = vm.V[y]
+	case 0x1:
+		vm.V[x] |= vm.V[y]
+		vm.resetVF()
+	case 0x2:
+		vm.V[x] &= vm.V[y]
+		vm.resetVF()
+	case 0x3:
+		vm.V[x] ^= vm.V[y]
+		vm.resetVF()
+	case 0x4:
+		sum := uint16(vm.V[x]) + uint16(vm.V[y])
+		vm.V[x] = byte(sum)
+		vm.V[0xF] = byte(sum >> 8)
+	case 0x5:
+		borrow := byte(0)
+		if vm.V[x] >= vm.V[y] {
+			borrow = 1
+		}
+		vm.V[x] -= vm.V[y]
+		vm.V[0xF] = borrow
+	case 0x6:
+		src := y
+		if vm.Quirks.ShiftVX {
+			src = x
+		}
+		carry := vm.V[src] & 0x1
+		vm.V[x] = vm.V[src] >> 1
+		vm.V[0xF] = carry
+	case 0x7:
+		borrow := byte(0)
+		if vm.V[y] >= vm.V[x] {
+			borrow = 1
+		}
+		vm.V[x] = vm.V[y] - vm.V[x]
+		vm.V[0xF] = borrow
+	case 0xE:
+		src := y
+		if vm.Quirks.ShiftVX {
+			src = x
+		}
+		carry := vm.V[src] >> 7
+		vm.V[x] = vm.V[src] << 1
+		vm.V[0xF] = carry
+	}
+}
+
+/// resetVF clears VF after 8XY1/8XY2/8XY3 when the active quirks profile
+/// expects the original COSMAC VIP's "OR/AND/XOR zero the flag" behavior.
+///
+func (vm *CHIP_8) resetVF() {
+	if vm.Quirks.ResetVF {
+		vm.V[0xF] = 0
+	}
+}
+
+func (vm *CHIP_8) opE(x, kk byte) {
+	switch kk {
+	case 0x9E:
+		if vm.Keys[vm.V[x]&0xF] {
+			vm.PC += 2
+		}
+	case 0xA1:
+		if !vm.Keys[vm.V[x]&0xF] {
+			vm.PC += 2
+		}
+	}
+}
+
+func (vm *CHIP_8) opF(op uint16, x, kk byte) {
+	switch {
+	case op == 0xF000:
+		hi := vm.Memory[vm.PC]
+		lo := vm.Memory[vm.PC+1]
+		vm.I = uint16(hi)<<8 | uint16(lo)
+		vm.PC += 2
+	case kk == 0x01: // FN01 - select drawing/scroll plane(s)
+		vm.Plane = x & 0x3
+	case kk == 0x02: // F002 - load 16-byte audio pattern buffer from I
+		copy(vm.Pattern[:], vm.Memory[vm.I:vm.I+16])
+		vm.PatternSet = true
+	case kk == 0x07:
+		vm.V[x] = vm.DT
+	case kk == 0x0A:
+		vm.waitKey = int(x)
+	case kk == 0x15:
+		vm.DT = vm.V[x]
+	case kk == 0x18:
+		vm.ST = vm.V[x]
+	case kk == 0x1E:
+		vm.I += uint16(vm.V[x])
+	case kk == 0x29:
+		vm.I = uint16(vm.V[x]&0xF) * 5
+	case kk == 0x30: // FX30 - point I at the 8x10 "big" font glyph
+		vm.I = uint16(fontBigOffset) + uint16(vm.V[x]&0xF)*10
+	case kk == 0x33:
+		value := vm.V[x]
+		vm.pokeMemory(vm.I, value/100)
+		vm.pokeMemory(vm.I+1, value/10%10)
+		vm.pokeMemory(vm.I+2, value%10)
+	case kk == 0x3A: // FX3A - set the audio pattern playback pitch
+		vm.Pitch = vm.V[x]
+	case kk == 0x55:
+		for i := byte(0); i <= x; i++ {
+			vm.pokeMemory(vm.I+uint16(i), vm.V[i])
+		}
+		if vm.Quirks.IncrementI {
+			vm.I += uint16(x) + 1
+		}
+	case kk == 0x65:
+		for i := byte(0); i <= x; i++ {
+			vm.V[i] = vm.Memory[vm.I+uint16(i)]
+		}
+		if vm.Quirks.IncrementI {
+			vm.I += uint16(x) + 1
+		}
+	case kk == 0x75: // FX75 - save V0..VX to the RPL flags and disk
+		copy(vm.RPL[:x+1], vm.V[:x+1])
+		vm.saveRPL()
+	case kk == 0x85: // FX85 - load V0..VX from the RPL flags
+		copy(vm.V[:x+1], vm.RPL[:x+1])
+	}
+}
@@ -0,0 +1,94 @@
+package chip8
+
+/// Quirks captures the handful of behaviors that differ between
+/// CHIP-8 interpreters and that ROMs frequently depend on one way or
+/// the other.
+///
+type Quirks struct {
+	/// ShiftVX makes 8XY6/8XYE shift VX in place (SCHIP/XO-CHIP)
+	/// instead of shifting VY into VX (the original COSMAC VIP).
+	///
+	ShiftVX bool
+
+	/// ResetVF makes 8XY1/8XY2/8XY3 (OR/AND/XOR) clear VF afterwards,
+	/// matching the original COSMAC VIP's CPU quirk.
+	///
+	ResetVF bool
+
+	/// IncrementI makes FX55/FX65 leave I advanced past the last
+	/// register stored/loaded, rather than restoring it afterwards.
+	///
+	IncrementI bool
+
+	/// JumpVX makes BNNN add VX (the top nibble of NNN selects the
+	/// register) instead of always adding V0.
+	///
+	JumpVX bool
+
+	/// DisplayWait makes DXYN block until the next 60Hz vblank before
+	/// drawing, as the COSMAC VIP's slow display access forced.
+	///
+	DisplayWait bool
+
+	/// Clipping makes sprites clip at the edge of the screen instead of
+	/// wrapping around to the opposite edge.
+	///
+	Clipping bool
+}
+
+/// QuirksVIP matches the original COSMAC VIP interpreter, which the
+/// majority of classic CHIP-8 ROMs were written against.
+///
+var QuirksVIP = Quirks{
+	ShiftVX:     false,
+	ResetVF:     true,
+	IncrementI:  true,
+	JumpVX:      false,
+	DisplayWait: true,
+	Clipping:    true,
+}
+
+/// QuirksSchip matches SCHIP 1.1, which most hi-res ROMs assume.
+///
+var QuirksSchip = Quirks{
+	ShiftVX:     true,
+	ResetVF:     false,
+	IncrementI:  false,
+	JumpVX:      true,
+	DisplayWait: false,
+	Clipping:    true,
+}
+
+/// QuirksXOChip matches Octo's XO-CHIP interpreter.
+///
+var QuirksXOChip = Quirks{
+	ShiftVX:     true,
+	ResetVF:     false,
+	IncrementI:  true,
+	JumpVX:      true,
+	DisplayWait: false,
+	Clipping:    false,
+}
+
+/// DefaultQuirks returns the conventional quirks profile for a mode,
+/// used when neither a quirks database entry nor a -quirks flag picks
+/// one.
+///
+func DefaultQuirks(mode Mode) Quirks {
+	switch mode {
+	case ModeSchip:
+		return QuirksSchip
+	case ModeXOChip:
+		return QuirksXOChip
+	default:
+		return QuirksVIP
+	}
+}
+
+/// SetQuirks overrides the VM's active quirks profile. Callers
+/// typically resolve one from -quirks, a per-ROM quirks database, or
+/// DefaultQuirks(vm.Mode) and apply it right after loading.
+///
+func (vm *CHIP_8) SetQuirks(q Quirks) {
+	vm.Quirks = q
+}
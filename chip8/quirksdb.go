@@ -0,0 +1,59 @@
+package chip8
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+)
+
+/// QuirksDB maps a ROM's SHA-256 (hex-encoded) to the name of the
+/// quirks profile known to make it behave correctly ("vip", "schip" or
+/// "xo"). It lets Load() pick a sensible default for well-known ROMs
+/// whose opcodes alone don't disambiguate the quirks they expect.
+///
+type QuirksDB map[string]string
+
+/// LoadQuirksDB reads a JSON quirks database of the form
+/// {"<sha256 hex>": "vip", ...} from disk.
+///
+func LoadQuirksDB(path string) (QuirksDB, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db := make(QuirksDB)
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+/// Lookup resolves the quirks profile for a loaded VM, if its ROM's
+/// SHA-256 is present in the database.
+///
+func (db QuirksDB) Lookup(vm *CHIP_8) (Quirks, bool) {
+	name, ok := db[hex.EncodeToString(vm.SHA256[:])]
+	if !ok {
+		return Quirks{}, false
+	}
+
+	return QuirksByName(name)
+}
+
+/// QuirksByName resolves one of the named built-in profiles, used by
+/// both the quirks database and the -quirks flag.
+///
+func QuirksByName(name string) (Quirks, bool) {
+	switch name {
+	case "vip":
+		return QuirksVIP, true
+	case "schip":
+		return QuirksSchip, true
+	case "xo", "xo-chip":
+		return QuirksXOChip, true
+	}
+
+	return Quirks{}, false
+}
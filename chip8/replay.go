@@ -0,0 +1,200 @@
+package chip8
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+/// replayMagic identifies a .c8r file.
+///
+const replayMagic = "C8R1"
+
+/// replayHeader is the JSON blob at the start of a .c8r file: enough to
+/// reproduce a run exactly, given the same ROM.
+///
+type replayHeader struct {
+	SHA256 string
+	Quirks Quirks
+	Seed   int64
+}
+
+/// ReplayWriter records every keypad change to a .c8r file as it
+/// happens, tagged with the frame it happened on, so a run's input can
+/// be played back later together with the RNG seed in the header. Only
+/// input and the seed are recorded: the number of instructions the VM
+/// runs between two frames is not.
+///
+type ReplayWriter struct {
+	f     *os.File
+	last  [16]bool
+	frame uint32
+}
+
+/// NewReplayWriter creates path and writes its header: the ROM's
+/// SHA-256, its quirks profile, and the RNG seed the caller is using
+/// for this run (so playback via OpenReplay can reuse it).
+///
+func NewReplayWriter(path string, vm *CHIP_8, seed int64) (*ReplayWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h := replayHeader{
+		SHA256: hex.EncodeToString(vm.SHA256[:]),
+		Quirks: vm.Quirks,
+		Seed:   seed,
+	}
+
+	if err := writeReplayHeader(f, h); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &ReplayWriter{f: f}, nil
+}
+
+func writeReplayHeader(f *os.File, h replayHeader) error {
+	body, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(replayMagic); err != nil {
+		return err
+	}
+
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(body)))
+
+	if _, err := f.Write(lenBuf[:]); err != nil {
+		return err
+	}
+
+	_, err = f.Write(body)
+	return err
+}
+
+/// Advance should be called once per simulated (60Hz) frame with the
+/// keypad state that frame ran with; it writes a delta record only when
+/// it changed since the last call.
+///
+func (w *ReplayWriter) Advance(keys [16]bool) error {
+	if keys != w.last {
+		var buf [6]byte
+		binary.BigEndian.PutUint32(buf[0:4], w.frame)
+		binary.BigEndian.PutUint16(buf[4:6], keysToBits(keys))
+
+		if _, err := w.f.Write(buf[:]); err != nil {
+			return err
+		}
+
+		w.last = keys
+	}
+
+	w.frame++
+
+	return nil
+}
+
+/// Close flushes and closes the underlying file.
+///
+func (w *ReplayWriter) Close() error {
+	return w.f.Close()
+}
+
+/// ReplayReader plays back a .c8r file, feeding its recorded keypad
+/// deltas into a VM frame by frame instead of live input.
+///
+type ReplayReader struct {
+	f      *os.File
+	Header replayHeader
+
+	cur       [16]bool
+	pending   bool
+	nextFrame uint32
+	nextKeys  [16]bool
+}
+
+/// OpenReplay reads a .c8r file's header and primes the first delta
+/// record, ready for Keys to be called as the VM advances.
+///
+func OpenReplay(path string) (*ReplayReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := readReplayHeader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r := &ReplayReader{f: f, Header: h}
+	r.fill()
+
+	return r, nil
+}
+
+func readReplayHeader(f *os.File) (replayHeader, error) {
+	var h replayHeader
+
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return h, err
+	}
+	if string(magic) != replayMagic {
+		return h, fmt.Errorf("replay: %s is not a .c8r file", f.Name())
+	}
+
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(f, lenBuf[:]); err != nil {
+		return h, err
+	}
+
+	body := make([]byte, binary.BigEndian.Uint32(lenBuf[:]))
+	if _, err := io.ReadFull(f, body); err != nil {
+		return h, err
+	}
+
+	err := json.Unmarshal(body, &h)
+	return h, err
+}
+
+func (r *ReplayReader) fill() {
+	var buf [6]byte
+
+	if _, err := io.ReadFull(r.f, buf[:]); err != nil {
+		r.pending = false
+		return
+	}
+
+	r.nextFrame = binary.BigEndian.Uint32(buf[0:4])
+	r.nextKeys = bitsToKeys(binary.BigEndian.Uint16(buf[4:6]))
+	r.pending = true
+}
+
+/// Keys returns the keypad state recorded for frame, advancing through
+/// any delta records up to and including it. Frames must be requested
+/// in non-decreasing order; once the file is exhausted, the last
+/// recorded state is held indefinitely.
+///
+func (r *ReplayReader) Keys(frame uint32) [16]bool {
+	for r.pending && r.nextFrame <= frame {
+		r.cur = r.nextKeys
+		r.fill()
+	}
+
+	return r.cur
+}
+
+/// Close closes the underlying file.
+///
+func (r *ReplayReader) Close() error {
+	return r.f.Close()
+}
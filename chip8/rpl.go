@@ -0,0 +1,40 @@
+package chip8
+
+import (
+	"io/ioutil"
+)
+
+/// rplPath returns the sidecar file FX75/FX85 persist RPL flags to,
+/// next to the ROM itself.
+///
+func (vm *CHIP_8) rplPath() string {
+	if vm.Path == "" {
+		return ""
+	}
+
+	return vm.Path + ".rpl"
+}
+
+/// saveRPL writes the current RPL flags to disk, ignoring failures (a
+/// ROM assembled in-memory or missing write permissions shouldn't crash
+/// the VM).
+///
+func (vm *CHIP_8) saveRPL() {
+	if path := vm.rplPath(); path != "" {
+		ioutil.WriteFile(path, vm.RPL[:], 0644)
+	}
+}
+
+/// loadRPL restores previously saved RPL flags, if a sidecar file
+/// exists for this ROM.
+///
+func (vm *CHIP_8) loadRPL() {
+	path := vm.rplPath()
+	if path == "" {
+		return
+	}
+
+	if data, err := ioutil.ReadFile(path); err == nil {
+		copy(vm.RPL[:], data)
+	}
+}
@@ -0,0 +1,166 @@
+package chip8
+
+/// Quantum selects how finely VM.Process divides up a running VM for
+/// the debugger: a whole instruction, a single fetch/decode/execute
+/// phase, or a whole video frame.
+///
+type Quantum int
+
+const (
+	QuantumInstruction Quantum = iota
+	QuantumCycle
+	QuantumFrame
+)
+
+/// DefaultHistoryCap bounds the step-back ring buffer; older snapshots
+/// are dropped FIFO once it's full.
+///
+const DefaultHistoryCap = 10000
+
+/// pixelRef records a single display pixel a step wrote, and its value
+/// beforehand, so StepBack can restore it exactly.
+///
+type pixelRef struct {
+	plane  int
+	index  int
+	before byte
+}
+
+/// snapshot is a compact, copy-on-write delta of what one executed
+/// instruction changed: registers, stack, timers, plane selection,
+/// resolution, written memory and flipped pixels. The RPL flags, the
+/// audio pattern and pitch, a pending FX0A wait and Exited are not
+/// recorded, so StepBack leaves them as they are.
+///
+type snapshot struct {
+	opcode uint16
+	pc     uint16 // PC before this step executed
+	v      [16]byte
+	i      uint16
+	stack  []uint16
+	dt, st byte
+	plane  byte
+	hires  bool
+	mem    map[uint16]byte // address -> value before this step wrote it
+	pix    []pixelRef
+}
+
+/// SetQuantum changes the stepping granularity used by Process and the
+/// debugger's single-step command.
+///
+func (vm *CHIP_8) SetQuantum(q Quantum) {
+	vm.Quantum = q
+	vm.phase = 0
+}
+
+/// SetHistoryCap resizes the step-back ring buffer, trimming the
+/// oldest entries if it's shrinking.
+///
+func (vm *CHIP_8) SetHistoryCap(n int) {
+	vm.HistoryCap = n
+
+	limit := n
+	if limit <= 0 {
+		limit = DefaultHistoryCap
+	}
+
+	if excess := len(vm.history) - limit; excess > 0 {
+		vm.history = vm.history[excess:]
+	}
+}
+
+func (vm *CHIP_8) beginStep() {
+	stack := make([]uint16, len(vm.Stack))
+	copy(stack, vm.Stack)
+
+	vm.cur = &snapshot{
+		pc:    vm.PC,
+		v:     vm.V,
+		i:     vm.I,
+		stack: stack,
+		dt:    vm.DT,
+		st:    vm.ST,
+		plane: vm.Plane,
+		hires: vm.Hires,
+		mem:   make(map[uint16]byte),
+	}
+}
+
+func (vm *CHIP_8) endStep(opcode uint16) {
+	vm.cur.opcode = opcode
+	vm.history = append(vm.history, vm.cur)
+	vm.cur = nil
+
+	limit := vm.HistoryCap
+	if limit <= 0 {
+		limit = DefaultHistoryCap
+	}
+
+	if excess := len(vm.history) - limit; excess > 0 {
+		vm.history = vm.history[excess:]
+	}
+}
+
+/// pokeMemory writes a byte to RAM, recording its prior value in the
+/// in-progress snapshot (the first time an address is touched this
+/// step) so StepBack can restore it.
+///
+func (vm *CHIP_8) pokeMemory(addr uint16, value byte) {
+	if vm.cur != nil {
+		if _, touched := vm.cur.mem[addr]; !touched {
+			vm.cur.mem[addr] = vm.Memory[addr]
+		}
+	}
+
+	vm.Memory[addr] = value
+}
+
+/// pokePixel writes a single display pixel, recording its prior value
+/// whenever the write changes it, so StepBack can restore it.
+///
+func (vm *CHIP_8) pokePixel(plane, index int, value byte) {
+	before := vm.Planes[plane][index]
+
+	if vm.cur != nil && before != value {
+		vm.cur.pix = append(vm.cur.pix, pixelRef{plane, index, before})
+	}
+
+	vm.Planes[plane][index] = value
+}
+
+/// StepBack rewinds the VM by exactly one recorded step, replaying its
+/// snapshot's inverse. It returns false once the ring buffer is empty.
+///
+func (vm *CHIP_8) StepBack() bool {
+	if len(vm.history) == 0 {
+		return false
+	}
+
+	last := vm.history[len(vm.history)-1]
+	vm.history = vm.history[:len(vm.history)-1]
+
+	vm.PC = last.pc
+	vm.V = last.v
+	vm.I = last.i
+	vm.Stack = last.stack
+	vm.DT = last.dt
+	vm.ST = last.st
+	vm.Plane = last.plane
+	vm.Hires = last.hires
+
+	for addr, value := range last.mem {
+		vm.Memory[addr] = value
+	}
+
+	for _, p := range last.pix {
+		vm.Planes[p.plane][p.index] = p.before
+	}
+
+	return true
+}
+
+/// HistoryLen reports how many steps can currently be rewound.
+///
+func (vm *CHIP_8) HistoryLen() int {
+	return len(vm.history)
+}
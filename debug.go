@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/massung/chip-8/chip8"
+	"github.com/massung/chip-8/frontend"
+)
+
+var (
+	/// Paused is true while single-stepping or stopped at a breakpoint;
+	/// the main loop stops advancing the VM while it's set.
+	///
+	Paused bool
+
+	/// log is a small scrollback of recent breakpoint/status messages
+	/// shown by the log panel.
+	///
+	log []string
+)
+
+const maxLogLines = 8
+
+/// InitDebug resets the debug log.
+///
+func InitDebug() {
+	log = nil
+}
+
+/// LogMessage appends a line to the debug log, trimming the oldest
+/// entries once it grows past maxLogLines. Uppercased to match the
+/// debug glyph font (frontend/sdl/overlay.go), which only covers
+/// uppercase letters.
+///
+func LogMessage(format string, args ...interface{}) {
+	log = append(log, strings.ToUpper(fmt.Sprintf(format, args...)))
+
+	if len(log) > maxLogLines {
+		log = log[len(log)-maxLogLines:]
+	}
+}
+
+/// DebugPanels lays out the log, disassembly and register panels at
+/// the same fixed positions the SDL frontend has always drawn them at,
+/// plus the quirks and history panels. Frontends that don't implement
+/// frontend.Overlay simply never ask for these.
+///
+func DebugPanels() []frontend.OverlayPanel {
+	return []frontend.OverlayPanel{
+		{X: 12, Y: 212, Lines: log},
+		{X: 406, Y: 11, Lines: assemblyLines()},
+		{X: 406, Y: 212, Lines: registerLines()},
+		{X: 406, Y: 160, Lines: quirksLines()},
+		{X: 406, Y: 194, Lines: historyLines()},
+	}
+}
+
+/// assemblyLines disassembles a handful of instructions around PC,
+/// highlighting the one about to execute.
+///
+func assemblyLines() []string {
+	pc := VM.PC
+	var lines []string
+
+	for i := -4; i <= 4; i++ {
+		addr := int(pc) + i*2
+		if addr < 0 || addr+1 >= len(VM.Memory) {
+			continue
+		}
+
+		op := uint16(VM.Memory[addr])<<8 | uint16(VM.Memory[addr+1])
+		prefix := "  "
+		if addr == int(pc) {
+			prefix = "> "
+		}
+
+		lines = append(lines, fmt.Sprintf("%s%03X %04X", prefix, addr, op))
+	}
+
+	return lines
+}
+
+/// registerLines renders V0-VF, I, PC, DT, ST and the stack depth.
+///
+func registerLines() []string {
+	var lines []string
+
+	for i, v := range VM.V {
+		lines = append(lines, fmt.Sprintf("V%X:%02X", i, v))
+	}
+
+	lines = append(lines, fmt.Sprintf("I:%04X", VM.I))
+	lines = append(lines, fmt.Sprintf("PC:%04X", VM.PC))
+	lines = append(lines, fmt.Sprintf("DT:%02X ST:%02X", VM.DT, VM.ST))
+	lines = append(lines, fmt.Sprintf("SP:%d", len(VM.Stack)))
+
+	return lines
+}
+
+/// quirksLines renders the active quirks profile, one flag per line, so
+/// the ToggleQuirk hotkeys have something to toggle against.
+///
+func quirksLines() []string {
+	q := VM.Quirks
+	flags := []struct {
+		label string
+		on    bool
+	}{
+		{"SHIFT-VX", q.ShiftVX},
+		{"RESET-VF", q.ResetVF},
+		{"INC-I", q.IncrementI},
+		{"JUMP-VX", q.JumpVX},
+		{"DISP-WAIT", q.DisplayWait},
+		{"CLIP", q.Clipping},
+	}
+
+	lines := make([]string, len(flags))
+	for i, f := range flags {
+		state := "-"
+		if f.on {
+			state = "X"
+		}
+
+		lines[i] = fmt.Sprintf("%s:%s", f.label, state)
+	}
+
+	return lines
+}
+
+/// quantumName renders a chip8.Quantum for the debug panel.
+///
+func quantumName(q chip8.Quantum) string {
+	switch q {
+	case chip8.QuantumCycle:
+		return "CYCLE"
+	case chip8.QuantumFrame:
+		return "FRAME"
+	default:
+		return "INSN"
+	}
+}
+
+/// historyLines renders the active stepping quantum and how many steps
+/// are available to StepBack.
+///
+func historyLines() []string {
+	return []string{fmt.Sprintf("Q:%s H:%d", quantumName(VM.Quantum), VM.HistoryLen())}
+}
+
+/// CycleQuantum rotates the VM's stepping quantum through
+/// instruction -> cycle -> frame, bound to HotkeyCycleQuantum.
+///
+func CycleQuantum() {
+	switch VM.Quantum {
+	case chip8.QuantumInstruction:
+		VM.SetQuantum(chip8.QuantumCycle)
+	case chip8.QuantumCycle:
+		VM.SetQuantum(chip8.QuantumFrame)
+	default:
+		VM.SetQuantum(chip8.QuantumInstruction)
+	}
+
+	LogMessage("quantum: %s", quantumName(VM.Quantum))
+}
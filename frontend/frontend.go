@@ -0,0 +1,83 @@
+// Package frontend abstracts the window, input and audio backend the
+// main loop drives, so go-sdl2 is one pluggable implementation rather
+// than the only option.
+package frontend
+
+/// Hotkey identifies a debugger/emulator action a backend's input
+/// handling recognized, decoupled from any particular key code so each
+/// backend can bind it to whatever's natural locally (SDL scancodes,
+/// terminal escape sequences, ...).
+///
+type Hotkey int
+
+const (
+	HotkeyHelp Hotkey = iota
+	HotkeyPause
+	HotkeyStep
+	HotkeyCycleQuantum
+	HotkeyStepBack
+	HotkeyToggleQuirkShiftVX
+	HotkeyToggleQuirkResetVF
+	HotkeyToggleQuirkIncrementI
+	HotkeyToggleQuirkJumpVX
+	HotkeyToggleQuirkDisplayWait
+	HotkeyToggleQuirkClipping
+)
+
+/// Frontend is the window/input/audio backend the main loop drives.
+/// PresentFramebuffer only ever sees the raw CHIP-8 display; richer
+/// backends may additionally implement Overlay to render the debugger
+/// chrome (register dump, disassembly, quirks, log) around it.
+///
+type Frontend interface {
+	/// Init acquires whatever resources the backend needs: a window, a
+	/// terminal in raw mode, an output file, etc.
+	///
+	Init() error
+
+	/// PollInput drains pending input, updating keys with the current
+	/// CHIP-8 keypad state and calling hotkey for every recognized
+	/// debugger hotkey pressed since the last call. It returns false
+	/// once the frontend wants the program to exit.
+	///
+	PollInput(keys *[16]bool, hotkey func(Hotkey)) bool
+
+	/// PresentFramebuffer draws one video frame: pix is w*h bytes, one
+	/// per pixel, holding the OR of every display plane's bit set at
+	/// that pixel (0-3; only XO-CHIP ever draws more than one plane).
+	///
+	PresentFramebuffer(pix []byte, w, h int)
+
+	/// Beep turns the beeper on or off, matching VM.ST > 0.
+	///
+	Beep(on bool)
+
+	/// SetAudioPattern updates the XO-CHIP F002 pattern buffer and FX3A
+	/// pitch register a backend should play through while beeping,
+	/// instead of its default tone. Only called once a ROM has actually
+	/// issued F002; backends with no audio device may ignore it.
+	///
+	SetAudioPattern(pattern [16]byte, pitch byte)
+
+	/// Shutdown releases whatever Init acquired.
+	///
+	Shutdown()
+}
+
+/// OverlayPanel is one labeled block of debug text positioned at a
+/// pixel offset from the top-left of the window, e.g. the register
+/// dump or the quirks panel.
+///
+type OverlayPanel struct {
+	X, Y  int
+	Lines []string
+}
+
+/// Overlay is implemented by frontends rich enough to render the
+/// debugger's chrome alongside the game framebuffer. main.go type-
+/// asserts for it so plainer backends (terminal, headless, glfw) just
+/// show the bare display.
+///
+type Overlay interface {
+	DrawOverlay(panels []OverlayPanel)
+}
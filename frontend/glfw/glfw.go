@@ -0,0 +1,180 @@
+//go:build glfw
+
+// Package glfw is a Frontend for systems without go-sdl2: a window and
+// GL-textured quad via go-gl/glfw and go-gl/gl, and keyboard input via
+// GLFW's callbacks. It has no audio device and no debug overlay (it
+// does not implement frontend.Overlay), so it's best suited to just
+// playing a ROM rather than debugging one. Only built when compiled
+// with -tags glfw, so a default build doesn't need GLFW/X11's dev
+// headers installed either.
+package glfw
+
+import (
+	"runtime"
+
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/massung/chip-8/frontend"
+)
+
+func init() {
+	runtime.LockOSThread()
+}
+
+/// keymap maps the standard QWERTY CHIP-8 layout (1234/qwer/asdf/zxcv)
+/// onto the hex keypad, matching frontend/sdl's.
+///
+var keymap = map[glfw.Key]int{
+	glfw.Key1: 0x1, glfw.Key2: 0x2, glfw.Key3: 0x3, glfw.Key4: 0xC,
+	glfw.KeyQ: 0x4, glfw.KeyW: 0x5, glfw.KeyE: 0x6, glfw.KeyR: 0xD,
+	glfw.KeyA: 0x7, glfw.KeyS: 0x8, glfw.KeyD: 0x9, glfw.KeyF: 0xE,
+	glfw.KeyZ: 0xA, glfw.KeyX: 0x0, glfw.KeyC: 0xB, glfw.KeyV: 0xF,
+}
+
+/// hotkeymap maps the debugger hotkeys, matching frontend/sdl's.
+///
+var hotkeymap = map[glfw.Key]frontend.Hotkey{
+	glfw.KeyF1: frontend.HotkeyHelp,
+	glfw.KeyF2: frontend.HotkeyPause,
+	glfw.KeyF5: frontend.HotkeyStep,
+	glfw.KeyF6: frontend.HotkeyCycleQuantum,
+	glfw.KeyF7: frontend.HotkeyStepBack,
+}
+
+/// Frontend is the go-gl/glfw backed frontend.Frontend implementation.
+///
+type Frontend struct {
+	window  *glfw.Window
+	texture uint32
+
+	keys   *[16]bool
+	hotkey func(frontend.Hotkey)
+}
+
+/// New allocates an unopened GLFW frontend; call Init before use.
+///
+func New() *Frontend {
+	return &Frontend{}
+}
+
+/// Init opens the window, an OpenGL context and the texture the
+/// framebuffer is uploaded into every frame.
+///
+func (f *Frontend) Init() error {
+	if err := glfw.Init(); err != nil {
+		return err
+	}
+
+	glfw.WindowHint(glfw.ContextVersionMajor, 2)
+	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+
+	window, err := glfw.CreateWindow(640, 320, "CHIP-8", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	window.MakeContextCurrent()
+
+	if err := gl.Init(); err != nil {
+		return err
+	}
+
+	window.SetKeyCallback(f.onKey)
+	f.window = window
+
+	gl.GenTextures(1, &f.texture)
+	gl.BindTexture(gl.TEXTURE_2D, f.texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+
+	return nil
+}
+
+/// Shutdown destroys the window and terminates GLFW.
+///
+func (f *Frontend) Shutdown() {
+	if f.window != nil {
+		f.window.Destroy()
+	}
+
+	glfw.Terminate()
+}
+
+/// PollInput processes queued GLFW key events (latched by onKey since
+/// the last call) and returns false once the window's close flag is set.
+///
+func (f *Frontend) PollInput(keys *[16]bool, hotkey func(frontend.Hotkey)) bool {
+	f.keys, f.hotkey = keys, hotkey
+
+	glfw.PollEvents()
+
+	return !f.window.ShouldClose()
+}
+
+func (f *Frontend) onKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if f.keys == nil {
+		return
+	}
+
+	if chipKey, ok := keymap[key]; ok {
+		f.keys[chipKey] = action != glfw.Release
+		return
+	}
+
+	if action != glfw.Press {
+		return
+	}
+
+	if hk, ok := hotkeymap[key]; ok && f.hotkey != nil {
+		f.hotkey(hk)
+	}
+}
+
+func (f *Frontend) Beep(on bool) {
+	// no audio device in this backend yet
+}
+
+func (f *Frontend) SetAudioPattern(pattern [16]byte, pitch byte) {
+	// no audio device in this backend yet
+}
+
+/// palette mirrors frontend/sdl's four-color display palette.
+///
+var palette = [4][3]byte{
+	{32, 42, 53},
+	{230, 230, 230},
+	{96, 192, 255},
+	{255, 196, 64},
+}
+
+/// PresentFramebuffer uploads pix as an RGB texture and draws it as a
+/// single screen-filling textured quad.
+///
+func (f *Frontend) PresentFramebuffer(pix []byte, w, h int) {
+	rgb := make([]byte, w*h*3)
+	for i, p := range pix {
+		color := palette[p&3]
+		rgb[i*3], rgb[i*3+1], rgb[i*3+2] = color[0], color[1], color[2]
+	}
+
+	fw, fh := f.window.GetFramebufferSize()
+	gl.Viewport(0, 0, int32(fw), int32(fh))
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+
+	gl.BindTexture(gl.TEXTURE_2D, f.texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGB, int32(w), int32(h), 0, gl.RGB, gl.UNSIGNED_BYTE, gl.Ptr(rgb))
+	gl.Enable(gl.TEXTURE_2D)
+
+	gl.Begin(gl.QUADS)
+	gl.TexCoord2f(0, 1)
+	gl.Vertex2f(-1, -1)
+	gl.TexCoord2f(1, 1)
+	gl.Vertex2f(1, -1)
+	gl.TexCoord2f(1, 0)
+	gl.Vertex2f(1, 1)
+	gl.TexCoord2f(0, 0)
+	gl.Vertex2f(-1, 1)
+	gl.End()
+
+	f.window.SwapBuffers()
+}
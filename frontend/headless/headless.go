@@ -0,0 +1,104 @@
+// Package headless is a Frontend that opens no window and reads no
+// input: it's what `-frontend=headless` selects for tests and CI, and
+// it optionally records every presented frame to an animated GIF for
+// `-record-gif`.
+package headless
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+
+	"github.com/massung/chip-8/frontend"
+)
+
+/// palette mirrors frontend/sdl's four-color display palette so a
+/// recorded GIF looks the same as the SDL window.
+///
+var palette = color.Palette{
+	color.RGBA{32, 42, 53, 255},
+	color.RGBA{230, 230, 230, 255},
+	color.RGBA{96, 192, 255, 255},
+	color.RGBA{255, 196, 64, 255},
+}
+
+/// Frontend presents frames to nowhere, except when GIFPath is set, in
+/// which case Shutdown writes every frame seen to it as an animated GIF.
+///
+type Frontend struct {
+	GIFPath string
+
+	frames []*image.Paletted
+	delays []int
+
+	frameCount   int // frames presented so far, for nextDelay's running total
+	emittedDelay int // sum of delays already appended to f.delays, in 1/100ths
+}
+
+/// New returns a headless frontend. gifPath may be empty, in which
+/// case frames are simply discarded.
+///
+func New(gifPath string) *Frontend {
+	return &Frontend{GIFPath: gifPath}
+}
+
+func (f *Frontend) Init() error {
+	return nil
+}
+
+/// PollInput never has input to offer; it just keeps the loop running.
+///
+func (f *Frontend) PollInput(keys *[16]bool, hotkey func(frontend.Hotkey)) bool {
+	return true
+}
+
+func (f *Frontend) Beep(on bool) {}
+
+func (f *Frontend) SetAudioPattern(pattern [16]byte, pitch byte) {}
+
+func (f *Frontend) Shutdown() {
+	if f.GIFPath == "" || len(f.frames) == 0 {
+		return
+	}
+
+	out, err := os.Create(f.GIFPath)
+	if err != nil {
+		return
+	}
+	defer out.Close()
+
+	gif.EncodeAll(out, &gif.GIF{Image: f.frames, Delay: f.delays})
+}
+
+/// PresentFramebuffer records pix as the next GIF frame when GIFPath
+/// was set; otherwise it's a no-op.
+///
+func (f *Frontend) PresentFramebuffer(pix []byte, w, h int) {
+	if f.GIFPath == "" {
+		return
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, w, h), palette)
+	copy(img.Pix, pix)
+
+	f.frames = append(f.frames, img)
+	f.delays = append(f.delays, f.nextDelay())
+}
+
+/// nextDelay returns this frame's GIF delay in 1/100ths of a second,
+/// matching the emulator's 60Hz video tick on average: 100/60 isn't a
+/// whole number of hundredths, so rather than truncating every frame to
+/// 1 (speeding up from 60fps to 100fps) it tracks the running total owed
+/// and emits whatever's left each time, spreading the rounding across
+/// neighboring frames (1, 2, 2, 1, 2, 2, ...).
+///
+func (f *Frontend) nextDelay() int {
+	f.frameCount++
+
+	total := f.frameCount * 100 / 60
+	delay := total - f.emittedDelay
+	f.emittedDelay = total
+
+	return delay
+}
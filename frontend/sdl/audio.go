@@ -0,0 +1,95 @@
+//go:build sdl
+
+package sdl
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/// sampleRate is the audio device's output rate, in Hz.
+///
+const sampleRate = 44100
+
+/// initAudio opens the default audio device for a simple square-wave
+/// beeper driven by Beep. Audio is best-effort: if no device is
+/// available the emulator still runs, silently.
+///
+func (f *Frontend) initAudio() {
+	spec := &sdl.AudioSpec{
+		Freq:     sampleRate,
+		Format:   sdl.AUDIO_S8,
+		Channels: 1,
+		Samples:  1024,
+		Callback: sdl.AudioCallback(sdl.NewAudioCallback(f.fillAudio)),
+	}
+
+	dev, err := sdl.OpenAudioDevice("", false, spec, nil, 0)
+	if err != nil {
+		fmt.Println("audio disabled:", err)
+		return
+	}
+
+	f.audioDevice = dev
+	sdl.PauseAudioDevice(f.audioDevice, false)
+}
+
+/// Beep starts or stops the beeper.
+///
+func (f *Frontend) Beep(on bool) {
+	if on && !f.beeping {
+		f.samplePos = 0
+	}
+
+	f.beeping = on
+}
+
+/// SetAudioPattern stores the XO-CHIP F002 pattern buffer and FX3A
+/// pitch so fillAudio plays them instead of the default square wave.
+///
+func (f *Frontend) SetAudioPattern(pattern [16]byte, pitch byte) {
+	f.pattern = pattern
+	f.pitch = pitch
+	f.customPattern = true
+}
+
+func (f *Frontend) fillAudio(out []byte) {
+	if !f.beeping {
+		for i := range out {
+			out[i] = 0
+		}
+
+		return
+	}
+
+	if !f.customPattern {
+		// classic CHIP-8/SCHIP: a fixed-pitch square wave.
+		period := 100
+		for i := range out {
+			if (i/period)%2 == 0 {
+				out[i] = 32
+			} else {
+				out[i] = 0
+			}
+		}
+
+		return
+	}
+
+	// XO-CHIP: step through the 128-bit pattern buffer at the rate
+	// FX3A's pitch selects, per the spec's 4000*2^((pitch-64)/48) Hz.
+	rate := 4000 * math.Pow(2, (float64(f.pitch)-64)/48)
+
+	for i := range out {
+		bit := uint64(float64(f.samplePos)*rate/sampleRate) % 128
+		if f.pattern[bit/8]&(0x80>>uint(bit%8)) != 0 {
+			out[i] = 32
+		} else {
+			out[i] = 0
+		}
+
+		f.samplePos++
+	}
+}
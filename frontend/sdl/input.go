@@ -0,0 +1,82 @@
+//go:build sdl
+
+package sdl
+
+import (
+	"github.com/massung/chip-8/frontend"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/// keymap maps the standard QWERTY CHIP-8 layout (1234/qwer/asdf/zxcv)
+/// onto the hex keypad.
+///
+var keymap = map[sdl.Keycode]int{
+	sdl.K_1: 0x1, sdl.K_2: 0x2, sdl.K_3: 0x3, sdl.K_4: 0xC,
+	sdl.K_q: 0x4, sdl.K_w: 0x5, sdl.K_e: 0x6, sdl.K_r: 0xD,
+	sdl.K_a: 0x7, sdl.K_s: 0x8, sdl.K_d: 0x9, sdl.K_f: 0xE,
+	sdl.K_z: 0xA, sdl.K_x: 0x0, sdl.K_c: 0xB, sdl.K_v: 0xF,
+}
+
+/// hotkeymap maps the debugger hotkeys that don't also toggle a quirk.
+///
+var hotkeymap = map[sdl.Keycode]frontend.Hotkey{
+	sdl.K_F1: frontend.HotkeyHelp,
+	sdl.K_F2: frontend.HotkeyPause,
+	sdl.K_F5: frontend.HotkeyStep,
+	sdl.K_F6: frontend.HotkeyCycleQuantum,
+	sdl.K_F7: frontend.HotkeyStepBack,
+}
+
+/// quirkHotkeymap maps the Ctrl+1..6 quirk-toggle hotkeys.
+///
+var quirkHotkeymap = map[sdl.Keycode]frontend.Hotkey{
+	sdl.K_1: frontend.HotkeyToggleQuirkShiftVX,
+	sdl.K_2: frontend.HotkeyToggleQuirkResetVF,
+	sdl.K_3: frontend.HotkeyToggleQuirkIncrementI,
+	sdl.K_4: frontend.HotkeyToggleQuirkJumpVX,
+	sdl.K_5: frontend.HotkeyToggleQuirkDisplayWait,
+	sdl.K_6: frontend.HotkeyToggleQuirkClipping,
+}
+
+/// PollInput drains the SDL event queue, updating keys and calling
+/// hotkey for every recognized debugger hotkey. It returns false once
+/// the window has been closed.
+///
+func (f *Frontend) PollInput(keys *[16]bool, hotkey func(frontend.Hotkey)) bool {
+	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+		switch e := event.(type) {
+		case *sdl.QuitEvent:
+			return false
+		case *sdl.KeyboardEvent:
+			handleKey(e, keys, hotkey)
+		}
+	}
+
+	return true
+}
+
+func handleKey(e *sdl.KeyboardEvent, keys *[16]bool, hotkey func(frontend.Hotkey)) {
+	down := e.Type == sdl.KEYDOWN
+	ctrl := e.Keysym.Mod&sdl.KMOD_CTRL != 0
+
+	if key, ok := keymap[e.Keysym.Sym]; ok && !ctrl {
+		keys[key] = down
+		return
+	}
+
+	if !down {
+		return
+	}
+
+	if ctrl {
+		if hk, ok := quirkHotkeymap[e.Keysym.Sym]; ok {
+			hotkey(hk)
+		}
+
+		return
+	}
+
+	if hk, ok := hotkeymap[e.Keysym.Sym]; ok {
+		hotkey(hk)
+	}
+}
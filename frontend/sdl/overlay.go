@@ -0,0 +1,92 @@
+//go:build sdl
+
+package sdl
+
+import (
+	"github.com/massung/chip-8/frontend"
+)
+
+/// glyphs is a tiny 3x5 bitmap font, just enough to label the debug
+/// panels; each byte is one row, the low 3 bits are pixels left-to-right.
+///
+var glyphs = map[rune][5]byte{
+	' ': {0, 0, 0, 0, 0},
+	'0': {0x7, 0x5, 0x5, 0x5, 0x7},
+	'1': {0x2, 0x6, 0x2, 0x2, 0x7},
+	'2': {0x7, 0x1, 0x7, 0x4, 0x7},
+	'3': {0x7, 0x1, 0x7, 0x1, 0x7},
+	'4': {0x5, 0x5, 0x7, 0x1, 0x1},
+	'5': {0x7, 0x4, 0x7, 0x1, 0x7},
+	'6': {0x7, 0x4, 0x7, 0x5, 0x7},
+	'7': {0x7, 0x1, 0x1, 0x1, 0x1},
+	'8': {0x7, 0x5, 0x7, 0x5, 0x7},
+	'9': {0x7, 0x5, 0x7, 0x1, 0x7},
+	'A': {0x2, 0x5, 0x7, 0x5, 0x5},
+	'B': {0x6, 0x5, 0x6, 0x5, 0x6},
+	'C': {0x3, 0x4, 0x4, 0x4, 0x3},
+	'D': {0x6, 0x5, 0x5, 0x5, 0x6},
+	'E': {0x7, 0x4, 0x6, 0x4, 0x7},
+	'F': {0x7, 0x4, 0x6, 0x4, 0x4},
+	'G': {0x3, 0x4, 0x5, 0x5, 0x3},
+	'H': {0x5, 0x5, 0x7, 0x5, 0x5},
+	'I': {0x7, 0x2, 0x2, 0x2, 0x7},
+	'J': {0x1, 0x1, 0x1, 0x5, 0x2},
+	'K': {0x5, 0x5, 0x6, 0x5, 0x5},
+	'L': {0x4, 0x4, 0x4, 0x4, 0x7},
+	'M': {0x5, 0x7, 0x5, 0x5, 0x5},
+	'N': {0x5, 0x7, 0x7, 0x5, 0x5},
+	'O': {0x7, 0x5, 0x5, 0x5, 0x7},
+	'P': {0x7, 0x5, 0x7, 0x4, 0x4},
+	'Q': {0x7, 0x5, 0x5, 0x7, 0x1},
+	'R': {0x7, 0x5, 0x6, 0x5, 0x5},
+	'S': {0x3, 0x4, 0x7, 0x1, 0x6},
+	'T': {0x7, 0x2, 0x2, 0x2, 0x2},
+	'U': {0x5, 0x5, 0x5, 0x5, 0x7},
+	'V': {0x5, 0x5, 0x5, 0x5, 0x2},
+	'W': {0x5, 0x5, 0x5, 0x7, 0x5},
+	'X': {0x5, 0x5, 0x2, 0x5, 0x5},
+	'Y': {0x5, 0x5, 0x2, 0x2, 0x2},
+	':': {0x0, 0x2, 0x0, 0x2, 0x0},
+	'.': {0x0, 0x0, 0x0, 0x0, 0x2},
+	'-': {0x0, 0x0, 0x7, 0x0, 0x0},
+	'>': {0x4, 0x2, 0x1, 0x2, 0x4},
+}
+
+/// drawText renders s at (x, y) using the 3x5 debug glyph set, 4 pixels
+/// per column.
+///
+func (f *Frontend) drawText(x, y int, s string) {
+	for _, r := range s {
+		glyph, ok := glyphs[r]
+		if !ok {
+			glyph = glyphs[' ']
+		}
+
+		for row := 0; row < 5; row++ {
+			bits := glyph[row]
+
+			for col := 0; col < 3; col++ {
+				if bits&(1<<uint(2-col)) != 0 {
+					f.renderer.DrawPoint(x+col, y+row)
+				}
+			}
+		}
+
+		x += 4
+	}
+}
+
+/// DrawOverlay renders each panel's lines with the debug glyph font,
+/// one line per 6 pixels of height, then presents the frame. This is
+/// always called once per video tick (even with no panels) since it's
+/// what actually flips the window's buffer; see PresentFramebuffer.
+///
+func (f *Frontend) DrawOverlay(panels []frontend.OverlayPanel) {
+	for _, p := range panels {
+		for i, line := range p.Lines {
+			f.drawText(p.X, p.Y+i*6, line)
+		}
+	}
+
+	f.renderer.Present()
+}
@@ -0,0 +1,62 @@
+//go:build sdl
+
+package sdl
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+/// palette holds the four colors a pixel can be: off, plane 0 only,
+/// plane 1 only, and both planes set at once (XO-CHIP only draws
+/// more than one plane at a time).
+///
+var palette = [4][3]byte{
+	{32, 42, 53},    // off
+	{230, 230, 230}, // plane 0
+	{96, 192, 255},  // plane 1
+	{255, 196, 64},  // both planes
+}
+
+/// PresentFramebuffer repaints the backing texture from pix and scales
+/// it to fit the game region of the window. It does not flip the
+/// window's buffer itself: this frontend always implements
+/// frontend.Overlay, and DrawOverlay (called right after, even when
+/// there's nothing to show) is what actually presents the frame, so the
+/// debug chrome is drawn before the swap rather than after.
+///
+func (f *Frontend) PresentFramebuffer(pix []byte, w, h int) {
+	f.renderer.SetDrawColor(32, 42, 53, 255)
+	f.renderer.Clear()
+
+	frame(f.renderer, 8, 8, 386, 194)
+	frame(f.renderer, 8, 208, 386, 164)
+	frame(f.renderer, 402, 8, 204, 194)
+	frame(f.renderer, 402, 208, 204, 164)
+
+	rgb := make([]byte, 128*64*3)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			color := palette[pix[y*w+x]&3]
+
+			o := (y*128 + x) * 3
+			rgb[o], rgb[o+1], rgb[o+2] = color[0], color[1], color[2]
+		}
+	}
+
+	f.screen.Update(nil, rgb, 128*3)
+
+	src := &sdl.Rect{W: int32(w), H: int32(h)}
+	dst := &sdl.Rect{X: 10, Y: 10, W: 384, H: 192}
+	f.renderer.Copy(f.screen, src, dst)
+}
+
+func frame(r *sdl.Renderer, x, y, w, h int) {
+	r.SetDrawColor(0, 0, 0, 255)
+	r.DrawLine(x, y, x+w, y)
+	r.DrawLine(x, y, x, y+h)
+
+	// highlight
+	r.SetDrawColor(95, 112, 120, 255)
+	r.DrawLine(x+w, y, x+w, y+h)
+	r.DrawLine(x, y+h, x+w, y+h)
+}
@@ -0,0 +1,91 @@
+//go:build sdl
+
+// Package sdl is the default Frontend, backed by go-sdl2: a window,
+// renderer, audio device and keyboard input, plus the pixel-font debug
+// overlay the debugger draws around the emulated screen. Only built
+// when compiled with -tags sdl, so a terminal/headless-only binary
+// doesn't need SDL2's dev headers installed.
+package sdl
+
+import (
+	"runtime"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func init() {
+	runtime.LockOSThread()
+}
+
+/// Frontend is the go-sdl2 backed frontend.Frontend implementation.
+///
+type Frontend struct {
+	window   *sdl.Window
+	renderer *sdl.Renderer
+	screen   *sdl.Texture
+
+	audioDevice sdl.AudioDeviceID
+	beeping     bool
+
+	pattern       [16]byte
+	pitch         byte
+	customPattern bool
+	samplePos     uint64
+}
+
+/// New allocates an unopened SDL frontend; call Init before use.
+///
+func New() *Frontend {
+	return &Frontend{}
+}
+
+/// Init opens the window, renderer, backing texture and audio device.
+///
+func (f *Frontend) Init() error {
+	if err := sdl.Init(sdl.INIT_VIDEO | sdl.INIT_AUDIO); err != nil {
+		return err
+	}
+
+	flags := sdl.WINDOW_OPENGL | sdl.WINDOWPOS_CENTERED
+	window, renderer, err := sdl.CreateWindowAndRenderer(614, 380, uint32(flags))
+	if err != nil {
+		return err
+	}
+
+	f.window = window
+	f.renderer = renderer
+
+	if icon, err := sdl.LoadBMP("data/chip_8.bmp"); err == nil {
+		mask := sdl.MapRGB(icon.Format, 255, 0, 255)
+		icon.SetColorKey(1, mask)
+		f.window.SetIcon(icon)
+	}
+
+	f.window.SetTitle("CHIP-8")
+
+	format := uint32(sdl.PIXELFORMAT_RGB24)
+	f.screen, err = f.renderer.CreateTexture(format, sdl.TEXTUREACCESS_STREAMING, 128, 64)
+	if err != nil {
+		return err
+	}
+
+	f.renderer.SetDrawColor(200, 208, 212, 255)
+
+	f.initAudio()
+
+	return nil
+}
+
+/// Shutdown closes the audio device and window.
+///
+func (f *Frontend) Shutdown() {
+	if f.audioDevice != 0 {
+		sdl.CloseAudioDevice(f.audioDevice)
+	}
+
+	if f.window != nil {
+		f.window.Destroy()
+	}
+
+	sdl.Quit()
+}
@@ -0,0 +1,86 @@
+// Package terminal is a display-only Frontend that renders the CHIP-8
+// framebuffer to stdout with half-block Unicode characters and 24-bit
+// ANSI color, for watching a ROM run over ssh or in a CI log without a
+// window. It reads no input; games play back whatever the VM does on
+// its own (handy for `-play`back of a .c8r, or just spectating).
+package terminal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/massung/chip-8/frontend"
+)
+
+/// palette mirrors frontend/sdl's four-color display palette.
+///
+var palette = [4][3]byte{
+	{32, 42, 53},
+	{230, 230, 230},
+	{96, 192, 255},
+	{255, 196, 64},
+}
+
+/// Frontend renders to the controlling terminal via ANSI escapes.
+///
+type Frontend struct{}
+
+/// New returns a terminal frontend.
+///
+func New() *Frontend {
+	return &Frontend{}
+}
+
+/// Init clears the screen and hides the cursor.
+///
+func (f *Frontend) Init() error {
+	fmt.Print("\x1b[2J\x1b[?25l")
+	return nil
+}
+
+/// PollInput always keeps the loop running; the terminal backend is
+/// spectator-only and has no way to read a keypress without a raw-mode
+/// terminal library this repo doesn't otherwise depend on.
+///
+func (f *Frontend) PollInput(keys *[16]bool, hotkey func(frontend.Hotkey)) bool {
+	return true
+}
+
+func (f *Frontend) Beep(on bool) {}
+
+func (f *Frontend) SetAudioPattern(pattern [16]byte, pitch byte) {}
+
+/// Shutdown shows the cursor again.
+///
+func (f *Frontend) Shutdown() {
+	fmt.Print("\x1b[?25h\n")
+}
+
+/// PresentFramebuffer redraws the whole frame in place using the
+/// Unicode half-block character: each terminal cell shows two vertical
+/// CHIP-8 pixels at once, one as the foreground color, one as the
+/// background.
+///
+func (f *Frontend) PresentFramebuffer(pix []byte, w, h int) {
+	var b strings.Builder
+
+	b.WriteString("\x1b[H")
+
+	for y := 0; y < h; y += 2 {
+		for x := 0; x < w; x++ {
+			top := palette[pix[y*w+x]&3]
+
+			bottom := top
+			if y+1 < h {
+				bottom = palette[pix[(y+1)*w+x]&3]
+			}
+
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀",
+				top[0], top[1], top[2], bottom[0], bottom[1], bottom[2])
+		}
+
+		b.WriteString("\x1b[0m\n")
+	}
+
+	fmt.Print(b.String())
+}
@@ -0,0 +1,12 @@
+//go:build glfw
+
+package main
+
+import (
+	"github.com/massung/chip-8/frontend"
+	"github.com/massung/chip-8/frontend/glfw"
+)
+
+func init() {
+	frontendFactories["glfw"] = func() frontend.Frontend { return glfw.New() }
+}
@@ -0,0 +1,12 @@
+//go:build sdl
+
+package main
+
+import (
+	"github.com/massung/chip-8/frontend"
+	sdlfrontend "github.com/massung/chip-8/frontend/sdl"
+)
+
+func init() {
+	frontendFactories["sdl"] = func() frontend.Frontend { return sdlfrontend.New() }
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/massung/chip-8/frontend"
+)
+
+/// handleHotkey applies the debugger action a frontend's PollInput
+/// recognized, independent of whatever key or escape sequence triggered
+/// it on that backend.
+///
+func handleHotkey(h frontend.Hotkey) {
+	switch h {
+	case frontend.HotkeyHelp:
+		LogMessage("F1: help, F2: pause, F5: step, F6: quantum, F7: step back, Ctrl+1..6: toggle quirks")
+	case frontend.HotkeyPause:
+		Paused = !Paused
+	case frontend.HotkeyStep:
+		if Paused {
+			VM.Step()
+		}
+	case frontend.HotkeyCycleQuantum:
+		CycleQuantum()
+	case frontend.HotkeyStepBack:
+		if !VM.StepBack() {
+			LogMessage("step-back: history empty")
+		}
+	case frontend.HotkeyToggleQuirkShiftVX:
+		ToggleQuirk("shift-vx")
+	case frontend.HotkeyToggleQuirkResetVF:
+		ToggleQuirk("reset-vf")
+	case frontend.HotkeyToggleQuirkIncrementI:
+		ToggleQuirk("increment-i")
+	case frontend.HotkeyToggleQuirkJumpVX:
+		ToggleQuirk("jump-vx")
+	case frontend.HotkeyToggleQuirkDisplayWait:
+		ToggleQuirk("display-wait")
+	case frontend.HotkeyToggleQuirkClipping:
+		ToggleQuirk("clipping")
+	}
+}
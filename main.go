@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"math/rand"
 	"path/filepath"
-	"runtime"
 	"time"
 
 	"github.com/massung/chip-8/chip8"
-	"github.com/veandco/go-sdl2/sdl"
+	"github.com/massung/chip-8/frontend"
+	"github.com/massung/chip-8/frontend/headless"
+	"github.com/massung/chip-8/frontend/terminal"
 )
 
 var (
@@ -25,56 +26,89 @@ var (
 	///
 	Break bool
 
+	/// The instruction set to emulate: "chip8", "schip" or "xo-chip".
+	/// Empty means auto-detect from the ROM's opcodes.
+	///
+	ModeFlag string
+
+	/// The window/input/audio backend: "sdl" (default), "glfw",
+	/// "terminal" or "headless". "sdl" and "glfw" are only available
+	/// when the binary was built with -tags sdl / -tags glfw.
+	///
+	FrontendFlag string
+
+	/// Path an animated GIF is written to on exit when running with
+	/// -frontend=headless; ignored otherwise.
+	///
+	RecordGIF string
+
+	/// Address to host a netplay session on (host:port), or empty.
+	///
+	NetplayHost string
+
+	/// Address of a netplay session to connect to (host:port), or empty.
+	///
+	NetplayConnect string
+
+	/// Path a .c8r replay of this run's input is written to, or empty.
+	///
+	RecordPath string
+
+	/// Path to a .c8r replay to play back instead of live input.
+	///
+	PlayPath string
+
 	/// The CHIP-8 virtual machine.
 	///
 	VM *chip8.CHIP_8
 
-	/// The SDL Window and Renderer.
+	/// The active window/input/audio backend.
 	///
-	Window *sdl.Window
-	Renderer *sdl.Renderer
-)
+	FE frontend.Frontend
 
-func init() {
-	runtime.LockOSThread()
-}
+	/// Non-nil once -netplay or -netplay-connect has established a
+	/// session; the main loop drives the VM through it a frame at a
+	/// time instead of calling VM.Process directly.
+	///
+	Netplay *chip8.Netplay
+
+	/// Non-nil while -record is writing out this run's input.
+	///
+	Recorder *chip8.ReplayWriter
+
+	/// Non-nil while -play is feeding recorded input into the VM
+	/// instead of the frontend's live input.
+	///
+	Replay *chip8.ReplayReader
+)
 
 func main() {
 	var err error
 
-	// seed the random number generator
-	rand.Seed(time.Now().UTC().UnixNano())
-
 	// parse the command line
 	flag.BoolVar(&Assemble, "a", false, "Assemble file before loading.")
 	flag.BoolVar(&Break, "b", false, "Start ROM paused.")
+	flag.StringVar(&ModeFlag, "mode", "", "Instruction set: chip8, schip, or xo-chip (default: auto-detect).")
+	flag.StringVar(&FrontendFlag, "frontend", "sdl", "Window backend: sdl, glfw, terminal, or headless (sdl and glfw require building with -tags).")
+	flag.StringVar(&RecordGIF, "record-gif", "", "With -frontend=headless, write every frame to this path as an animated GIF on exit.")
+	flag.StringVar(&NetplayHost, "netplay", "", "Host a netplay session, listening for one peer on host:port.")
+	flag.StringVar(&NetplayConnect, "netplay-connect", "", "Connect to a netplay session hosted at host:port.")
+	flag.StringVar(&RecordPath, "record", "", "Record this run's input to a .c8r replay file.")
+	flag.StringVar(&PlayPath, "play", "", "Play back a .c8r replay file instead of live input.")
+	RegisterQuirksFlags()
 	flag.Parse()
 
 	// get the file name of the ROM to load
 	File = flag.Arg(0)
 
-	// initialize SDL or panic
-	if err = sdl.Init(sdl.INIT_VIDEO | sdl.INIT_AUDIO); err != nil {
+	if FE, err = newFrontend(); err != nil {
 		panic(err)
 	}
 
-	// create the main window and renderer or panic
-	flags := sdl.WINDOW_OPENGL | sdl.WINDOWPOS_CENTERED
-	if Window, Renderer, err = sdl.CreateWindowAndRenderer(614, 380, uint32(flags)); err != nil {
+	if err = FE.Init(); err != nil {
 		panic(err)
 	}
-
-	// set the icon
-	if icon, err := sdl.LoadBMP("data/chip_8.bmp"); err == nil {
-		mask := sdl.MapRGB(icon.Format, 255, 0, 255)
-
-		// create the mask color key and set the icon
-		icon.SetColorKey(1, mask)
-		Window.SetIcon(icon)
-	}
-
-	// set the title
-	Window.SetTitle("CHIP-8")
+	defer FE.Shutdown()
 
 	// initialize subsystems
 	InitDebug()
@@ -93,10 +127,24 @@ func main() {
 
 	// create a new CHIP-8 virtual machine, load the ROM..
 	Load()
+	VM.SetQuirks(ResolveQuirks(VM))
 
-	InitScreen()
-	InitAudio()
-	InitFont()
+	if err = connectNetplay(); err != nil {
+		panic(err)
+	}
+	if Netplay != nil {
+		defer Netplay.Close()
+	}
+
+	if err = openReplay(); err != nil {
+		panic(err)
+	}
+	if Replay != nil {
+		defer Replay.Close()
+	}
+	if Recorder != nil {
+		defer Recorder.Close()
+	}
 
 	// initially break into debugger?
 	Paused = Break
@@ -105,15 +153,51 @@ func main() {
 	clock := time.NewTicker(time.Millisecond * 2)
 	video := time.NewTicker(time.Second / 60)
 
+	// netplay and replay playback both need to drive VM.Keys themselves
+	// rather than have the frontend's live input clobber it
+	var localKeys, discard [16]bool
+	keys := &VM.Keys
+	switch {
+	case Netplay != nil:
+		keys = &localKeys
+	case Replay != nil:
+		keys = &discard
+	}
+
+	var frame uint32
+
 	// notify that the main loop has started
 	fmt.Println("\nStarting program; press F1 for help")
 
-	// loop until window closed or user quit
-	for ProcessEvents() {
+	// loop until window closed, user quit, or the ROM exits (SCHIP 00FD)
+	for FE.PollInput(keys, handleHotkey) && !VM.Exited {
 		select {
 		case <-video.C:
+			switch {
+			case Netplay != nil:
+				if err := Netplay.Advance(localKeys); err != nil {
+					fmt.Println("netplay:", err)
+				}
+			case Replay != nil:
+				VM.Keys = Replay.Keys(frame)
+				VM.Tick60()
+			default:
+				VM.Tick60()
+			}
+
+			if Recorder != nil {
+				if err := Recorder.Advance(VM.Keys); err != nil {
+					fmt.Println("record:", err)
+				}
+			}
+
+			frame++
 			Refresh()
 		case <-clock.C:
+			if Netplay != nil {
+				continue // Netplay.Advance steps the VM itself, one frame at a time
+			}
+
 			res := VM.Process(Paused)
 
 			switch res.(type) {
@@ -127,6 +211,131 @@ func main() {
 	}
 }
 
+/// openReplay seeds the RNG and, depending on -play/-record, opens a
+/// .c8r file to read input from or write it to. -play's header seed
+/// always wins so a recorded run replays with its RNG seed; otherwise a fresh
+/// time-based seed is used (and recorded, if -record is set).
+///
+func openReplay() error {
+	if RecordPath != "" && PlayPath != "" {
+		return fmt.Errorf("-record and -play are mutually exclusive")
+	}
+
+	seed := time.Now().UTC().UnixNano()
+
+	if PlayPath != "" {
+		r, err := openReplayVerified(PlayPath, VM)
+		if err != nil {
+			return err
+		}
+
+		Replay = r
+		seed = r.Header.Seed
+
+		fmt.Println("play: reproducing", PlayPath)
+	}
+
+	rand.Seed(seed)
+
+	if RecordPath != "" {
+		w, err := chip8.NewReplayWriter(RecordPath, VM, seed)
+		if err != nil {
+			return err
+		}
+
+		Recorder = w
+		fmt.Println("record: writing to", RecordPath)
+	}
+
+	return nil
+}
+
+/// openReplayVerified opens a .c8r file, confirms it was recorded
+/// against the ROM currently loaded, and applies its recorded quirks
+/// profile to vm so playback can't silently desync against whatever
+/// -quirks/the quirks DB resolved to instead. (It's separate from
+/// openReplay just to keep the seed/record bookkeeping there free of
+/// hex-encoding noise.)
+///
+func openReplayVerified(path string, vm *chip8.CHIP_8) (*chip8.ReplayReader, error) {
+	r, err := chip8.OpenReplay(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Header.SHA256 != fmt.Sprintf("%x", vm.SHA256) {
+		r.Close()
+		return nil, fmt.Errorf("replay: %s was recorded against a different ROM", path)
+	}
+
+	if r.Header.Quirks != vm.Quirks {
+		fmt.Println("play: applying the quirks profile recorded in", path)
+		vm.SetQuirks(r.Header.Quirks)
+	}
+
+	return r, nil
+}
+
+/// connectNetplay hosts or joins a netplay session if -netplay or
+/// -netplay-connect was given; at most one of the two may be set.
+///
+func connectNetplay() error {
+	switch {
+	case NetplayHost != "" && NetplayConnect != "":
+		return fmt.Errorf("-netplay and -netplay-connect are mutually exclusive")
+	case NetplayHost != "":
+		fmt.Println("netplay: waiting for a peer on", NetplayHost)
+
+		np, err := chip8.Host(VM, NetplayHost)
+		if err != nil {
+			return err
+		}
+
+		Netplay = np
+		fmt.Println("netplay: connected")
+	case NetplayConnect != "":
+		fmt.Println("netplay: connecting to", NetplayConnect)
+
+		np, err := chip8.Connect(VM, NetplayConnect)
+		if err != nil {
+			return err
+		}
+
+		Netplay = np
+		fmt.Println("netplay: connected")
+	}
+
+	return nil
+}
+
+/// frontendFactories maps a -frontend name to its constructor. Backends
+/// that need C libraries (SDL, GLFW) register themselves here via
+/// init() from build-tag-gated files in this package, so a plain
+/// `go build .` doesn't need SDL2 or GLFW/X11 dev headers installed
+/// just to get a terminal/headless-only binary; building with
+/// -tags "sdl glfw" pulls them back in.
+///
+var frontendFactories = map[string]func() frontend.Frontend{
+	"terminal": func() frontend.Frontend { return terminal.New() },
+	"headless": func() frontend.Frontend { return headless.New(RecordGIF) },
+}
+
+/// newFrontend builds the backend selected by -frontend.
+///
+func newFrontend() (frontend.Frontend, error) {
+	name := FrontendFlag
+	if name == "" {
+		name = "sdl"
+	}
+
+	factory, ok := frontendFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown or not built-in -frontend %q (built with -tags %q?)", name, "sdl glfw")
+	}
+
+	return factory(), nil
+}
+
 func Load() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -138,54 +347,84 @@ func Load() {
 	}()
 
 	if File == "" {
-		VM = chip8.LoadROM(chip8.Pong)
+		VM = loadROM(chip8.Pong)
 	} else {
 		if Assemble {
 			asm := chip8.Assemble(File)
 
 			// load the assembled program
-			VM = chip8.LoadROM(asm.ROM)
+			VM = loadROM(asm.ROM)
 
 			// add all the breakpoints from the assembly
 			for _, b := range asm.Breakpoints {
 				VM.AddBreakpoint(b)
 			}
 		} else {
-			VM = chip8.LoadFile(File)
+			VM = loadFile(File)
 		}
 	}
 }
 
-func Refresh() {
-	Renderer.SetDrawColor(32, 42, 53, 255)
-	Renderer.Clear()
-
-	// frame various portions of the app
-	Frame(8, 8, 386, 194)
-	Frame(8, 208, 386, 164)
-	Frame(402, 8, 204, 194)
-	Frame(402, 208, 204, 164)
-
-	// update the video screen and copy it
-	RefreshScreen()
-	CopyScreen(10, 10, 384, 192)
-
-	// debug assembly and virtual registers
-	DebugLog(12, 212)
-	DebugAssembly(406, 11)
-	DebugRegisters(406, 212)
-
-	// show the new frame
-	Renderer.Present()
+/// mode resolves the -mode flag (if given) to a chip8.Mode, so Load()
+/// can pin the instruction set instead of auto-detecting it.
+///
+func mode() (chip8.Mode, bool) {
+	switch ModeFlag {
+	case "":
+		return 0, false
+	case "chip8":
+		return chip8.ModeChip8, true
+	case "schip":
+		return chip8.ModeSchip, true
+	case "xo-chip":
+		return chip8.ModeXOChip, true
+	}
+
+	panic(fmt.Sprintf("unknown -mode %q", ModeFlag))
 }
 
-func Frame(x, y, w, h int) {
-	Renderer.SetDrawColor(0, 0, 0, 255)
-	Renderer.DrawLine(x, y, x + w, y)
-	Renderer.DrawLine(x, y, x, y + h)
+func loadROM(data []byte) *chip8.CHIP_8 {
+	if m, pinned := mode(); pinned {
+		return chip8.LoadROMMode(data, m)
+	}
+
+	return chip8.LoadROM(data)
+}
 
-	// highlight
-	Renderer.SetDrawColor(95, 112, 120, 255)
-	Renderer.DrawLine(x + w, y, x + w, y + h)
-	Renderer.DrawLine(x, y + h, x + w, y + h)
+func loadFile(path string) *chip8.CHIP_8 {
+	if m, pinned := mode(); pinned {
+		return chip8.LoadFileMode(path, m)
+	}
+
+	return chip8.LoadFile(path)
+}
+
+/// framebuffer packs the VM's active display planes into one byte per
+/// pixel (the OR of every plane's bit there) for a Frontend to present,
+/// sized to whatever resolution the VM is currently running at.
+///
+func framebuffer() (pix []byte, w, h int) {
+	w, h = VM.Width(), VM.Height()
+	plane0, plane1 := VM.DisplayPlane(0), VM.DisplayPlane(1)
+
+	pix = make([]byte, w*h)
+	for i := range pix {
+		pix[i] = plane0[i] | plane1[i]<<1
+	}
+
+	return
+}
+
+func Refresh() {
+	pix, w, h := framebuffer()
+	FE.PresentFramebuffer(pix, w, h)
+	FE.Beep(VM.ST > 0)
+
+	if VM.PatternSet {
+		FE.SetAudioPattern(VM.Pattern, VM.Pitch)
+	}
+
+	if overlay, ok := FE.(frontend.Overlay); ok {
+		overlay.DrawOverlay(DebugPanels())
+	}
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/massung/chip-8/chip8"
+)
+
+const quirksDBPath = "data/quirks.json"
+
+var (
+	/// QuirksFlag names a built-in profile (vip, schip, xo) to use
+	/// instead of the one DefaultQuirks/the quirks database would pick.
+	///
+	QuirksFlag string
+
+	quirkShiftVX     bool
+	quirkResetVF     bool
+	quirkIncrementI  bool
+	quirkJumpVX      bool
+	quirkDisplayWait bool
+	quirkClipping    bool
+)
+
+/// RegisterQuirksFlags wires up -quirks and the individual
+/// -quirk-shift-vx-style override flags. Call before flag.Parse.
+///
+func RegisterQuirksFlags() {
+	flag.StringVar(&QuirksFlag, "quirks", "", "Quirks profile: vip, schip, or xo (default: auto).")
+	flag.BoolVar(&quirkShiftVX, "quirk-shift-vx", false, "8XY6/8XYE shift VX instead of VY.")
+	flag.BoolVar(&quirkResetVF, "quirk-reset-vf", false, "8XY1/8XY2/8XY3 clear VF afterwards.")
+	flag.BoolVar(&quirkIncrementI, "quirk-increment-i", false, "FX55/FX65 leave I advanced.")
+	flag.BoolVar(&quirkJumpVX, "quirk-jump-vx", false, "BNNN adds VX instead of V0.")
+	flag.BoolVar(&quirkDisplayWait, "quirk-display-wait", false, "DXYN blocks until the next vblank.")
+	flag.BoolVar(&quirkClipping, "quirk-clipping", false, "Sprites clip at the screen edge instead of wrapping.")
+}
+
+/// ResolveQuirks picks the quirks profile for a freshly loaded VM:
+/// DefaultQuirks for its mode, overridden by a quirks database hit for
+/// its ROM's SHA-256, overridden by -quirks, overridden by any
+/// individual -quirk-* flag the user actually passed.
+///
+func ResolveQuirks(vm *chip8.CHIP_8) chip8.Quirks {
+	q := chip8.DefaultQuirks(vm.Mode)
+
+	if db, err := chip8.LoadQuirksDB(quirksDBPath); err == nil {
+		if dbq, ok := db.Lookup(vm); ok {
+			q = dbq
+		}
+	}
+
+	if QuirksFlag != "" {
+		named, ok := chip8.QuirksByName(QuirksFlag)
+		if !ok {
+			panic(fmt.Sprintf("unknown -quirks %q", QuirksFlag))
+		}
+
+		q = named
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "quirk-shift-vx":
+			q.ShiftVX = quirkShiftVX
+		case "quirk-reset-vf":
+			q.ResetVF = quirkResetVF
+		case "quirk-increment-i":
+			q.IncrementI = quirkIncrementI
+		case "quirk-jump-vx":
+			q.JumpVX = quirkJumpVX
+		case "quirk-display-wait":
+			q.DisplayWait = quirkDisplayWait
+		case "quirk-clipping":
+			q.Clipping = quirkClipping
+		}
+	})
+
+	return q
+}
+
+/// ToggleQuirk flips a single quirk at runtime (bound to hotkeys in
+/// input.go) and logs the new state.
+///
+func ToggleQuirk(name string) {
+	q := VM.Quirks
+
+	switch name {
+	case "shift-vx":
+		q.ShiftVX = !q.ShiftVX
+	case "reset-vf":
+		q.ResetVF = !q.ResetVF
+	case "increment-i":
+		q.IncrementI = !q.IncrementI
+	case "jump-vx":
+		q.JumpVX = !q.JumpVX
+	case "display-wait":
+		q.DisplayWait = !q.DisplayWait
+	case "clipping":
+		q.Clipping = !q.Clipping
+	}
+
+	VM.SetQuirks(q)
+	LogMessage("quirk %s: %v", name, q)
+}